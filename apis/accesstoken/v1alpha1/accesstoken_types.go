@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A TokenPermission is a permission an AccessToken can be granted.
+// +kubebuilder:validation:Enum=PROJECT_READ;PROJECT_WRITE;PROJECT_ADMIN;REPO_READ;REPO_WRITE;REPO_ADMIN
+type TokenPermission string
+
+// AccessTokenParameters are the configurable fields of an AccessToken. The
+// token is scoped to either a user, a project or a repository of a project.
+// +kubebuilder:validation:XValidation:rule="has(self.user) != has(self.projectKey)",message="exactly one of user or projectKey must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.repositorySlug) || has(self.projectKey)",message="repositorySlug requires projectKey"
+type AccessTokenParameters struct {
+	// ProjectKey is the key of the project the token grants access to.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey,omitempty"`
+
+	// RepositorySlug is the slug of the repository the token grants access
+	// to.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug,omitempty"`
+
+	// User is the name of the user the token acts as.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="user is immutable"
+	User string `json:"user,omitempty"`
+
+	// Name of the token. Defaults to the name of the managed resource.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Permissions granted to the token.
+	// +kubebuilder:validation:MinItems=1
+	Permissions []TokenPermission `json:"permissions"`
+
+	// ExpiryDays is the number of days the token is valid for. The token is
+	// created again once it expired. The default of the server applies when
+	// omitted.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="expiryDays is immutable"
+	ExpiryDays *int `json:"expiryDays,omitempty"`
+}
+
+// AccessTokenObservation are the observable fields of an AccessToken.
+type AccessTokenObservation struct {
+	ID          string       `json:"id,omitempty"`
+	CreatedDate *metav1.Time `json:"createdDate,omitempty"`
+	ExpiryDate  *metav1.Time `json:"expiryDate,omitempty"`
+}
+
+// An AccessTokenSpec defines the desired state of an AccessToken.
+type AccessTokenSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       AccessTokenParameters `json:"forProvider"`
+}
+
+// An AccessTokenStatus represents the observed state of an AccessToken.
+type AccessTokenStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AccessTokenObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AccessToken is a Bitbucket HTTP access token. The token is published as
+// connection secret when it is created. Its external name is the ID of the
+// token.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="USER",type="string",JSONPath=".spec.forProvider.user"
+// +kubebuilder:printcolumn:name="EXPIRES",type="date",JSONPath=".status.atProvider.expiryDate"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type AccessToken struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessTokenSpec   `json:"spec"`
+	Status AccessTokenStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccessTokenList contains a list of AccessToken
+type AccessTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessToken `json:"items"`
+}
+
+// AccessToken type metadata.
+var (
+	AccessTokenKind             = reflect.TypeOf(AccessToken{}).Name()
+	AccessTokenGroupKind        = schema.GroupKind{Group: Group, Kind: AccessTokenKind}.String()
+	AccessTokenKindAPIVersion   = AccessTokenKind + "." + SchemeGroupVersion.String()
+	AccessTokenGroupVersionKind = SchemeGroupVersion.WithKind(AccessTokenKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&AccessToken{}, &AccessTokenList{})
+}
@@ -20,15 +20,25 @@ package apis
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 
+	accesstokenv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/accesstoken/v1alpha1"
+	branchv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/branch/v1alpha1"
 	projectv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
+	pullrequestv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/pullrequest/v1alpha1"
+	repositoryv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
 	bitbucketserverv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	webhookv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/webhook/v1alpha1"
 )
 
 func init() {
 	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
 	AddToSchemes = append(AddToSchemes,
 		bitbucketserverv1alpha1.SchemeBuilder.AddToScheme,
+		accesstokenv1alpha1.SchemeBuilder.AddToScheme,
+		branchv1alpha1.SchemeBuilder.AddToScheme,
 		projectv1alpha1.SchemeBuilder.AddToScheme,
+		pullrequestv1alpha1.SchemeBuilder.AddToScheme,
+		repositoryv1alpha1.SchemeBuilder.AddToScheme,
+		webhookv1alpha1.SchemeBuilder.AddToScheme,
 	)
 }
 
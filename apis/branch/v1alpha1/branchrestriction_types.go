@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// BranchMatcher selects the refs a BranchRestriction applies to.
+type BranchMatcher struct {
+	// Type of the matcher. BRANCH matches a single branch, PATTERN matches
+	// refs against a wildcard pattern, MODEL_CATEGORY matches a branching
+	// model category (e.g. FEATURE) and MODEL_BRANCH a branching model
+	// branch (e.g. production).
+	// +kubebuilder:validation:Enum=BRANCH;PATTERN;MODEL_CATEGORY;MODEL_BRANCH
+	Type string `json:"type"`
+
+	// ID of the matched ref, pattern, category or model branch. Branch names
+	// may be given without the refs/heads/ prefix.
+	ID string `json:"id"`
+}
+
+// BranchRestrictionParameters are the configurable fields of a BranchRestriction.
+type BranchRestrictionParameters struct {
+	// ProjectKey is the key of the project the restriction applies to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// RepositorySlug is the slug of the repository the restriction applies
+	// to. The restriction applies to every repository of the project when
+	// omitted.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug,omitempty"`
+
+	// Type of the restriction.
+	// +kubebuilder:validation:Enum=read-only;no-deletes;fast-forward-only;pull-request-only
+	Type string `json:"type"`
+
+	// Matcher selects the refs the restriction applies to.
+	Matcher BranchMatcher `json:"matcher"`
+
+	// Users are the names of the users exempted from the restriction.
+	// +optional
+	Users []string `json:"users,omitempty"`
+
+	// Groups are the names of the groups exempted from the restriction.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// AccessKeyIDs are the IDs of the access keys exempted from the
+	// restriction.
+	// +optional
+	AccessKeyIDs []int `json:"accessKeyIds,omitempty"`
+}
+
+// BranchRestrictionObservation are the observable fields of a BranchRestriction.
+type BranchRestrictionObservation struct {
+	ID int `json:"id,omitempty"`
+}
+
+// A BranchRestrictionSpec defines the desired state of a BranchRestriction.
+type BranchRestrictionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       BranchRestrictionParameters `json:"forProvider"`
+}
+
+// A BranchRestrictionStatus represents the observed state of a BranchRestriction.
+type BranchRestrictionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          BranchRestrictionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A BranchRestriction restricts the changes that can be made to the matching
+// refs of a Bitbucket project or repository. Its external name is the ID of
+// the restriction.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.forProvider.type"
+// +kubebuilder:printcolumn:name="MATCHER",type="string",JSONPath=".spec.forProvider.matcher.id"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type BranchRestriction struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BranchRestrictionSpec   `json:"spec"`
+	Status BranchRestrictionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BranchRestrictionList contains a list of BranchRestriction
+type BranchRestrictionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BranchRestriction `json:"items"`
+}
+
+// BranchRestriction type metadata.
+var (
+	BranchRestrictionKind             = reflect.TypeOf(BranchRestriction{}).Name()
+	BranchRestrictionGroupKind        = schema.GroupKind{Group: Group, Kind: BranchRestrictionKind}.String()
+	BranchRestrictionKindAPIVersion   = BranchRestrictionKind + "." + SchemeGroupVersion.String()
+	BranchRestrictionGroupVersionKind = SchemeGroupVersion.WithKind(BranchRestrictionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&BranchRestriction{}, &BranchRestrictionList{})
+}
@@ -27,7 +27,14 @@ import (
 
 // ProjectParameters are the configurable fields of a Project.
 type ProjectParameters struct {
+	// Key of the project. Changing the key moves the project, which changes
+	// the URLs of all of its repositories.
 	Key string `json:"key"`
+
+	// Name of the project.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
 	// +optional
 	Public bool `json:"public,omitempty"`
 	// +optional
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ProjectAccessKeyParameters are the configurable fields of a ProjectAccessKey.
+type ProjectAccessKeyParameters struct {
+	// ProjectKey is the key of the project the key grants access to. Either
+	// ProjectKey, ProjectRef or ProjectSelector has to be set.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey,omitempty"`
+
+	// ProjectRef references the Project the key grants access to.
+	// +optional
+	ProjectRef *xpv1.Reference `json:"projectRef,omitempty"`
+
+	// ProjectSelector selects the Project the key grants access to.
+	// +optional
+	ProjectSelector *xpv1.Selector `json:"projectSelector,omitempty"`
+
+	// PublicKeySecretRef references the public SSH key to grant access to,
+	// in the authorized_keys format. A keypair is generated when omitted and
+	// its private key is published as connection secret.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="publicKeySecretRef is immutable"
+	PublicKeySecretRef *xpv1.SecretKeySelector `json:"publicKeySecretRef,omitempty"`
+
+	// Label of the key. Defaults to the name of the managed resource.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="label is immutable"
+	Label string `json:"label,omitempty"`
+
+	// Permission granted to the key.
+	// +kubebuilder:validation:Enum=PROJECT_READ;PROJECT_WRITE
+	Permission string `json:"permission"`
+}
+
+// ProjectAccessKeyObservation are the observable fields of a ProjectAccessKey.
+type ProjectAccessKeyObservation struct {
+	ID         int    `json:"id,omitempty"`
+	Permission string `json:"permission,omitempty"`
+}
+
+// A ProjectAccessKeySpec defines the desired state of a ProjectAccessKey.
+type ProjectAccessKeySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ProjectAccessKeyParameters `json:"forProvider"`
+}
+
+// A ProjectAccessKeyStatus represents the observed state of a ProjectAccessKey.
+type ProjectAccessKeyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ProjectAccessKeyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProjectAccessKey grants an SSH key access to every repository of a
+// Bitbucket project. Its external name is the ID of the key.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="ID",type="integer",JSONPath=".status.atProvider.id"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type ProjectAccessKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectAccessKeySpec   `json:"spec"`
+	Status ProjectAccessKeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProjectAccessKeyList contains a list of ProjectAccessKey
+type ProjectAccessKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectAccessKey `json:"items"`
+}
+
+// ProjectAccessKey type metadata.
+var (
+	ProjectAccessKeyKind             = reflect.TypeOf(ProjectAccessKey{}).Name()
+	ProjectAccessKeyGroupKind        = schema.GroupKind{Group: Group, Kind: ProjectAccessKeyKind}.String()
+	ProjectAccessKeyKindAPIVersion   = ProjectAccessKeyKind + "." + SchemeGroupVersion.String()
+	ProjectAccessKeyGroupVersionKind = SchemeGroupVersion.WithKind(ProjectAccessKeyKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&ProjectAccessKey{}, &ProjectAccessKeyList{})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ProjectGroupPermissionParameters are the configurable fields of a ProjectGroupPermission.
+type ProjectGroupPermissionParameters struct {
+	// ProjectKey is the key of the project to grant the permission on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// Group is the name of the group to grant the permission to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="group is immutable"
+	Group string `json:"group"`
+
+	// Permission granted to the group.
+	// +kubebuilder:validation:Enum=PROJECT_READ;PROJECT_WRITE;PROJECT_ADMIN
+	Permission string `json:"permission"`
+}
+
+// ProjectGroupPermissionObservation are the observable fields of a ProjectGroupPermission.
+type ProjectGroupPermissionObservation struct {
+	Permission string `json:"permission,omitempty"`
+}
+
+// A ProjectGroupPermissionSpec defines the desired state of a ProjectGroupPermission.
+type ProjectGroupPermissionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ProjectGroupPermissionParameters `json:"forProvider"`
+}
+
+// A ProjectGroupPermissionStatus represents the observed state of a ProjectGroupPermission.
+type ProjectGroupPermissionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ProjectGroupPermissionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProjectGroupPermission grants a group access to a Bitbucket project.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="GROUP",type="string",JSONPath=".spec.forProvider.group"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type ProjectGroupPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectGroupPermissionSpec   `json:"spec"`
+	Status ProjectGroupPermissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProjectGroupPermissionList contains a list of ProjectGroupPermission
+type ProjectGroupPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectGroupPermission `json:"items"`
+}
+
+// ProjectGroupPermission type metadata.
+var (
+	ProjectGroupPermissionKind             = reflect.TypeOf(ProjectGroupPermission{}).Name()
+	ProjectGroupPermissionGroupKind        = schema.GroupKind{Group: Group, Kind: ProjectGroupPermissionKind}.String()
+	ProjectGroupPermissionKindAPIVersion   = ProjectGroupPermissionKind + "." + SchemeGroupVersion.String()
+	ProjectGroupPermissionGroupVersionKind = SchemeGroupVersion.WithKind(ProjectGroupPermissionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&ProjectGroupPermission{}, &ProjectGroupPermissionList{})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ProjectUserPermissionParameters are the configurable fields of a ProjectUserPermission.
+type ProjectUserPermissionParameters struct {
+	// ProjectKey is the key of the project to grant the permission on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// User is the name of the user to grant the permission to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="user is immutable"
+	User string `json:"user"`
+
+	// Permission granted to the user.
+	// +kubebuilder:validation:Enum=PROJECT_READ;PROJECT_WRITE;PROJECT_ADMIN
+	Permission string `json:"permission"`
+}
+
+// ProjectUserPermissionObservation are the observable fields of a ProjectUserPermission.
+type ProjectUserPermissionObservation struct {
+	Permission string `json:"permission,omitempty"`
+}
+
+// A ProjectUserPermissionSpec defines the desired state of a ProjectUserPermission.
+type ProjectUserPermissionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ProjectUserPermissionParameters `json:"forProvider"`
+}
+
+// A ProjectUserPermissionStatus represents the observed state of a ProjectUserPermission.
+type ProjectUserPermissionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ProjectUserPermissionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProjectUserPermission grants a user access to a Bitbucket project.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="USER",type="string",JSONPath=".spec.forProvider.user"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type ProjectUserPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectUserPermissionSpec   `json:"spec"`
+	Status ProjectUserPermissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProjectUserPermissionList contains a list of ProjectUserPermission
+type ProjectUserPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectUserPermission `json:"items"`
+}
+
+// ProjectUserPermission type metadata.
+var (
+	ProjectUserPermissionKind             = reflect.TypeOf(ProjectUserPermission{}).Name()
+	ProjectUserPermissionGroupKind        = schema.GroupKind{Group: Group, Kind: ProjectUserPermissionKind}.String()
+	ProjectUserPermissionKindAPIVersion   = ProjectUserPermissionKind + "." + SchemeGroupVersion.String()
+	ProjectUserPermissionGroupVersionKind = SchemeGroupVersion.WithKind(ProjectUserPermissionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&ProjectUserPermission{}, &ProjectUserPermissionList{})
+}
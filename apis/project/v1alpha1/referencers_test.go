@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+func TestProjectAccessKeyResolveReferences(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = SchemeBuilder.AddToScheme(scheme)
+
+	project := &Project{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}
+	meta.SetExternalName(project, "TEAM")
+	unready := &Project{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}}
+
+	type want struct {
+		params ProjectAccessKeyParameters
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		params ProjectAccessKeyParameters
+		want   want
+	}{
+		"ProjectKey": {
+			reason: "We should keep a project key that is set directly.",
+			params: ProjectAccessKeyParameters{ProjectKey: "PRJ"},
+			want:   want{params: ProjectAccessKeyParameters{ProjectKey: "PRJ"}},
+		},
+		"ProjectRef": {
+			reason: "We should resolve the key of the referenced project from its external name.",
+			params: ProjectAccessKeyParameters{ProjectRef: &xpv1.Reference{Name: "team-a"}},
+			want: want{params: ProjectAccessKeyParameters{
+				ProjectKey: "TEAM",
+				ProjectRef: &xpv1.Reference{Name: "team-a"},
+			}},
+		},
+		"ProjectSelector": {
+			reason: "We should resolve the key of the selected project and reference it.",
+			params: ProjectAccessKeyParameters{ProjectSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "a"}}},
+			want: want{params: ProjectAccessKeyParameters{
+				ProjectKey:      "TEAM",
+				ProjectRef:      &xpv1.Reference{Name: "team-a"},
+				ProjectSelector: &xpv1.Selector{MatchLabels: map[string]string{"team": "a"}},
+			}},
+		},
+		"ProjectNotFound": {
+			reason: "We should return an error if the referenced project does not exist.",
+			params: ProjectAccessKeyParameters{ProjectRef: &xpv1.Reference{Name: "team-c"}},
+			want: want{
+				params: ProjectAccessKeyParameters{ProjectRef: &xpv1.Reference{Name: "team-c"}},
+				err:    errors.Wrap(errors.New(`cannot get referenced resource: projects.project.bitbucketserver.crossplane.io "team-c" not found`), "spec.forProvider.projectKey"),
+			},
+		},
+		"ProjectNotReady": {
+			reason: "We should return an error if the referenced project has no key yet.",
+			params: ProjectAccessKeyParameters{ProjectRef: &xpv1.Reference{Name: "team-b"}},
+			want: want{
+				params: ProjectAccessKeyParameters{ProjectRef: &xpv1.Reference{Name: "team-b"}},
+				err:    errors.Wrap(errors.New("referenced field was empty (referenced resource may not yet be ready)"), "spec.forProvider.projectKey"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := kubefake.NewClientBuilder().WithScheme(scheme).WithObjects(project.DeepCopy(), unready.DeepCopy()).Build()
+			cr := &ProjectAccessKey{Spec: ProjectAccessKeySpec{ForProvider: tc.params}}
+			err := cr.ResolveReferences(context.Background(), kube)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncr.ResolveReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.params, cr.Spec.ForProvider); diff != "" {
+				t.Errorf("\n%s\ncr.ResolveReferences(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RefMatcher selects the source or target refs of the pull requests a
+// DefaultReviewerCondition applies to.
+type RefMatcher struct {
+	// Type of the matcher. ANY_REF matches every ref, BRANCH matches a single
+	// branch, PATTERN matches refs against a wildcard pattern, MODEL_CATEGORY
+	// matches a branching model category (e.g. FEATURE) and MODEL_BRANCH a
+	// branching model branch (e.g. production).
+	// +kubebuilder:validation:Enum=ANY_REF;BRANCH;PATTERN;MODEL_CATEGORY;MODEL_BRANCH
+	Type string `json:"type"`
+
+	// ID of the matched ref, pattern, category or model branch. Branch names
+	// may be given without the refs/heads/ prefix. Not used by ANY_REF.
+	// +optional
+	ID string `json:"id,omitempty"`
+}
+
+// DefaultReviewerConditionParameters are the configurable fields of a DefaultReviewerCondition.
+// +kubebuilder:validation:XValidation:rule="self.requiredApprovals <= size(self.users)",message="requiredApprovals cannot exceed the number of users"
+type DefaultReviewerConditionParameters struct {
+	// ProjectKey is the key of the project the condition applies to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// RepositorySlug is the slug of the repository the condition applies to.
+	// The condition applies to every repository of the project when omitted.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug,omitempty"`
+
+	// SourceMatcher selects the refs pull requests are opened from.
+	SourceMatcher RefMatcher `json:"sourceMatcher"`
+
+	// TargetMatcher selects the refs pull requests are opened to.
+	TargetMatcher RefMatcher `json:"targetMatcher"`
+
+	// Users are the names of the users added as reviewers.
+	// +kubebuilder:validation:MinItems=1
+	Users []string `json:"users"`
+
+	// RequiredApprovals is the number of the users that have to approve a
+	// pull request before it can be merged.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	RequiredApprovals int `json:"requiredApprovals"`
+}
+
+// DefaultReviewerConditionObservation are the observable fields of a DefaultReviewerCondition.
+type DefaultReviewerConditionObservation struct {
+	ID int `json:"id,omitempty"`
+}
+
+// A DefaultReviewerConditionSpec defines the desired state of a DefaultReviewerCondition.
+type DefaultReviewerConditionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DefaultReviewerConditionParameters `json:"forProvider"`
+}
+
+// A DefaultReviewerConditionStatus represents the observed state of a DefaultReviewerCondition.
+type DefaultReviewerConditionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          DefaultReviewerConditionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DefaultReviewerCondition adds reviewers to the pull requests between the
+// matching refs of a Bitbucket project or repository. Its external name is
+// the ID of the condition.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="APPROVALS",type="integer",JSONPath=".spec.forProvider.requiredApprovals"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type DefaultReviewerCondition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DefaultReviewerConditionSpec   `json:"spec"`
+	Status DefaultReviewerConditionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DefaultReviewerConditionList contains a list of DefaultReviewerCondition
+type DefaultReviewerConditionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DefaultReviewerCondition `json:"items"`
+}
+
+// DefaultReviewerCondition type metadata.
+var (
+	DefaultReviewerConditionKind             = reflect.TypeOf(DefaultReviewerCondition{}).Name()
+	DefaultReviewerConditionGroupKind        = schema.GroupKind{Group: Group, Kind: DefaultReviewerConditionKind}.String()
+	DefaultReviewerConditionKindAPIVersion   = DefaultReviewerConditionKind + "." + SchemeGroupVersion.String()
+	DefaultReviewerConditionGroupVersionKind = SchemeGroupVersion.WithKind(DefaultReviewerConditionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&DefaultReviewerCondition{}, &DefaultReviewerConditionList{})
+}
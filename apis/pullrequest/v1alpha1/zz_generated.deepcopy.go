@@ -0,0 +1,171 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerCondition) DeepCopyInto(out *DefaultReviewerCondition) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerCondition.
+func (in *DefaultReviewerCondition) DeepCopy() *DefaultReviewerCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DefaultReviewerCondition) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerConditionList) DeepCopyInto(out *DefaultReviewerConditionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DefaultReviewerCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerConditionList.
+func (in *DefaultReviewerConditionList) DeepCopy() *DefaultReviewerConditionList {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerConditionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DefaultReviewerConditionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerConditionObservation) DeepCopyInto(out *DefaultReviewerConditionObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerConditionObservation.
+func (in *DefaultReviewerConditionObservation) DeepCopy() *DefaultReviewerConditionObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerConditionObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerConditionParameters) DeepCopyInto(out *DefaultReviewerConditionParameters) {
+	*out = *in
+	out.SourceMatcher = in.SourceMatcher
+	out.TargetMatcher = in.TargetMatcher
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerConditionParameters.
+func (in *DefaultReviewerConditionParameters) DeepCopy() *DefaultReviewerConditionParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerConditionParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerConditionSpec) DeepCopyInto(out *DefaultReviewerConditionSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerConditionSpec.
+func (in *DefaultReviewerConditionSpec) DeepCopy() *DefaultReviewerConditionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerConditionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultReviewerConditionStatus) DeepCopyInto(out *DefaultReviewerConditionStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.AtProvider = in.AtProvider
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultReviewerConditionStatus.
+func (in *DefaultReviewerConditionStatus) DeepCopy() *DefaultReviewerConditionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultReviewerConditionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RefMatcher) DeepCopyInto(out *RefMatcher) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RefMatcher.
+func (in *RefMatcher) DeepCopy() *RefMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(RefMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
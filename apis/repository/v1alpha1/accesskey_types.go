@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AccessKeyParameters are the configurable fields of an AccessKey.
+type AccessKeyParameters struct {
+	// ProjectKey is the key of the project the repository belongs to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// RepositorySlug is the slug of the repository the key grants access to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug"`
+
+	// PublicKeySecretRef references the public SSH key to grant access to,
+	// in the authorized_keys format. A keypair is generated when omitted and
+	// its private key is published as connection secret.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="publicKeySecretRef is immutable"
+	PublicKeySecretRef *xpv1.SecretKeySelector `json:"publicKeySecretRef,omitempty"`
+
+	// Label of the key. Defaults to the name of the managed resource.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="label is immutable"
+	Label string `json:"label,omitempty"`
+
+	// Permission granted to the key.
+	// +kubebuilder:validation:Enum=REPO_READ;REPO_WRITE
+	Permission string `json:"permission"`
+}
+
+// AccessKeyObservation are the observable fields of an AccessKey.
+type AccessKeyObservation struct {
+	ID         int    `json:"id,omitempty"`
+	Permission string `json:"permission,omitempty"`
+}
+
+// An AccessKeySpec defines the desired state of an AccessKey.
+type AccessKeySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       AccessKeyParameters `json:"forProvider"`
+}
+
+// An AccessKeyStatus represents the observed state of an AccessKey.
+type AccessKeyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AccessKeyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AccessKey grants an SSH key access to a Bitbucket repository. Its
+// external name is the ID of the key.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type AccessKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessKeySpec   `json:"spec"`
+	Status AccessKeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccessKeyList contains a list of AccessKey
+type AccessKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessKey `json:"items"`
+}
+
+// AccessKey type metadata.
+var (
+	AccessKeyKind             = reflect.TypeOf(AccessKey{}).Name()
+	AccessKeyGroupKind        = schema.GroupKind{Group: Group, Kind: AccessKeyKind}.String()
+	AccessKeyKindAPIVersion   = AccessKeyKind + "." + SchemeGroupVersion.String()
+	AccessKeyGroupVersionKind = SchemeGroupVersion.WithKind(AccessKeyKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&AccessKey{}, &AccessKeyList{})
+}
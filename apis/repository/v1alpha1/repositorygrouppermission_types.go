@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RepositoryGroupPermissionParameters are the configurable fields of a RepositoryGroupPermission.
+type RepositoryGroupPermissionParameters struct {
+	// ProjectKey is the key of the project the repository belongs to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// RepositorySlug is the slug of the repository to grant the permission on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug"`
+
+	// Group is the name of the group to grant the permission to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="group is immutable"
+	Group string `json:"group"`
+
+	// Permission granted to the group.
+	// +kubebuilder:validation:Enum=REPO_READ;REPO_WRITE;REPO_ADMIN
+	Permission string `json:"permission"`
+}
+
+// RepositoryGroupPermissionObservation are the observable fields of a RepositoryGroupPermission.
+type RepositoryGroupPermissionObservation struct {
+	Permission string `json:"permission,omitempty"`
+}
+
+// A RepositoryGroupPermissionSpec defines the desired state of a RepositoryGroupPermission.
+type RepositoryGroupPermissionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RepositoryGroupPermissionParameters `json:"forProvider"`
+}
+
+// A RepositoryGroupPermissionStatus represents the observed state of a RepositoryGroupPermission.
+type RepositoryGroupPermissionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RepositoryGroupPermissionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A RepositoryGroupPermission grants a group access to a Bitbucket repository.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="GROUP",type="string",JSONPath=".spec.forProvider.group"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type RepositoryGroupPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositoryGroupPermissionSpec   `json:"spec"`
+	Status RepositoryGroupPermissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryGroupPermissionList contains a list of RepositoryGroupPermission
+type RepositoryGroupPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositoryGroupPermission `json:"items"`
+}
+
+// RepositoryGroupPermission type metadata.
+var (
+	RepositoryGroupPermissionKind             = reflect.TypeOf(RepositoryGroupPermission{}).Name()
+	RepositoryGroupPermissionGroupKind        = schema.GroupKind{Group: Group, Kind: RepositoryGroupPermissionKind}.String()
+	RepositoryGroupPermissionKindAPIVersion   = RepositoryGroupPermissionKind + "." + SchemeGroupVersion.String()
+	RepositoryGroupPermissionGroupVersionKind = SchemeGroupVersion.WithKind(RepositoryGroupPermissionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&RepositoryGroupPermission{}, &RepositoryGroupPermissionList{})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RepositoryUserPermissionParameters are the configurable fields of a RepositoryUserPermission.
+type RepositoryUserPermissionParameters struct {
+	// ProjectKey is the key of the project the repository belongs to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="projectKey is immutable"
+	ProjectKey string `json:"projectKey"`
+
+	// RepositorySlug is the slug of the repository to grant the permission on.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="repositorySlug is immutable"
+	RepositorySlug string `json:"repositorySlug"`
+
+	// User is the name of the user to grant the permission to.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="user is immutable"
+	User string `json:"user"`
+
+	// Permission granted to the user.
+	// +kubebuilder:validation:Enum=REPO_READ;REPO_WRITE;REPO_ADMIN
+	Permission string `json:"permission"`
+}
+
+// RepositoryUserPermissionObservation are the observable fields of a RepositoryUserPermission.
+type RepositoryUserPermissionObservation struct {
+	Permission string `json:"permission,omitempty"`
+}
+
+// A RepositoryUserPermissionSpec defines the desired state of a RepositoryUserPermission.
+type RepositoryUserPermissionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RepositoryUserPermissionParameters `json:"forProvider"`
+}
+
+// A RepositoryUserPermissionStatus represents the observed state of a RepositoryUserPermission.
+type RepositoryUserPermissionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RepositoryUserPermissionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A RepositoryUserPermission grants a user access to a Bitbucket repository.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPOSITORY",type="string",JSONPath=".spec.forProvider.repositorySlug"
+// +kubebuilder:printcolumn:name="USER",type="string",JSONPath=".spec.forProvider.user"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".spec.forProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,bitbucketserver}
+type RepositoryUserPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositoryUserPermissionSpec   `json:"spec"`
+	Status RepositoryUserPermissionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryUserPermissionList contains a list of RepositoryUserPermission
+type RepositoryUserPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositoryUserPermission `json:"items"`
+}
+
+// RepositoryUserPermission type metadata.
+var (
+	RepositoryUserPermissionKind             = reflect.TypeOf(RepositoryUserPermission{}).Name()
+	RepositoryUserPermissionGroupKind        = schema.GroupKind{Group: Group, Kind: RepositoryUserPermissionKind}.String()
+	RepositoryUserPermissionKindAPIVersion   = RepositoryUserPermissionKind + "." + SchemeGroupVersion.String()
+	RepositoryUserPermissionGroupVersionKind = SchemeGroupVersion.WithKind(RepositoryUserPermissionKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&RepositoryUserPermission{}, &RepositoryUserPermissionList{})
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types of a ProviderConfig.
+const (
+	// TypeTokenRotation indicates whether the access token the provider
+	// authenticates with is being rotated successfully.
+	TypeTokenRotation xpv1.ConditionType = "TokenRotation"
+
+	// TypeCredentialsValid indicates whether the credentials of the
+	// ProviderConfig can be read and are well formed.
+	TypeCredentialsValid xpv1.ConditionType = "CredentialsValid"
+)
+
+// Reasons a condition of a ProviderConfig is in its state.
+const (
+	ReasonRotationSucceeded xpv1.ConditionReason = "RotationSucceeded"
+	ReasonRotationFailed    xpv1.ConditionReason = "RotationFailed"
+
+	ReasonCredentialsValid       xpv1.ConditionReason = "CredentialsValid"
+	ReasonCredentialsUnavailable xpv1.ConditionReason = "CredentialsUnavailable"
+	ReasonCredentialsMalformed   xpv1.ConditionReason = "CredentialsMalformed"
+
+	ReasonHealthy   xpv1.ConditionReason = "Healthy"
+	ReasonUnhealthy xpv1.ConditionReason = "Unhealthy"
+)
+
+// Reasons a managed resource is in its state.
+const (
+	ReasonUnsupportedServerVersion xpv1.ConditionReason = "UnsupportedServerVersion"
+)
+
+// RotationSucceeded returns a condition indicating that the access token is
+// valid and will be replaced before it expires.
+func RotationSucceeded() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTokenRotation,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRotationSucceeded,
+	}
+}
+
+// RotationFailed returns a condition indicating that the access token could
+// not be rotated.
+func RotationFailed(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTokenRotation,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRotationFailed,
+		Message:            err.Error(),
+	}
+}
+
+// CredentialsValid returns a condition indicating that the credentials of the
+// ProviderConfig are well formed.
+func CredentialsValid() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeCredentialsValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCredentialsValid,
+	}
+}
+
+// CredentialsUnavailable returns a condition indicating that the credentials
+// of the ProviderConfig could not be read.
+func CredentialsUnavailable(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeCredentialsValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCredentialsUnavailable,
+		Message:            err.Error(),
+	}
+}
+
+// CredentialsMalformed returns a condition indicating that the credentials of
+// the ProviderConfig are not in a supported format.
+func CredentialsMalformed(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeCredentialsValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCredentialsMalformed,
+		Message:            err.Error(),
+	}
+}
+
+// Healthy returns a condition indicating that the provider can authenticate
+// against the server of the ProviderConfig.
+func Healthy() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthy,
+	}
+}
+
+// Unhealthy returns a condition indicating that the server of the
+// ProviderConfig cannot be reached, or does not accept its credentials.
+func Unhealthy(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnhealthy,
+		Message:            err.Error(),
+	}
+}
+
+// UnsupportedServerVersion returns a condition indicating that a managed
+// resource cannot be created, as the bitbucket server does not support it in
+// its version.
+func UnsupportedServerVersion(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnsupportedServerVersion,
+		Message:            err.Error(),
+	}
+}
@@ -29,10 +29,192 @@ import (
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+	// AuthType selects how credentials in the legacy format authenticate.
+	// Basic credentials are the base64 encoding of username:password, where
+	// the password may also be an HTTP access token. Bearer credentials are
+	// an HTTP access token. Credentials in the JSON format, holding either a
+	// username and password or a token and optionally its username, select
+	// the way to authenticate themselves.
+	// +optional
+	// +kubebuilder:default=Basic
+	AuthType AuthType `json:"authType,omitempty"`
 	// Base Url of bitbucket server
 	BaseURL string `json:"baseurl"`
+	// TLS configures how the connection to the server is secured.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// Proxy configures the HTTP(S) proxy requests to the server go through.
+	// The proxy configured by the environment of the provider is used when
+	// omitted.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+	// Headers are sent with every request to the server, e.g. for an API
+	// gateway in front of it. They cannot replace the Authorization header.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Retry configures how requests that failed transiently, through
+	// connection errors or 429, 502, 503 and 504 responses, are retried. Only
+	// idempotent requests are retried.
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// RateLimit bounds the rate of requests to the server. The limit is
+	// shared by all controllers, and by all ProviderConfigs of the same
+	// server, the configuration read last taking effect. Requests are not
+	// limited when omitted.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// Rotation configures the provider to replace the HTTP access token it
+	// authenticates with before the token expires.
+	// +optional
+	Rotation *TokenRotation `json:"rotation,omitempty"`
 }
 
+// TokenRotation configures the rotation of the HTTP access token the provider
+// authenticates with. Rotation requires the credentials to be read from a
+// Secret and to name the user the token belongs to, so Bearer credentials
+// holding nothing but the token cannot be rotated. Replacement tokens are
+// written back to that Secret, which is annotated with the ID of the token it
+// holds.
+// +kubebuilder:validation:XValidation:rule="!has(self.rotateBefore) || !has(self.expiryDays) || duration(self.rotateBefore) < duration(string(self.expiryDays * 24) + 'h')",message="rotateBefore must be shorter than expiryDays"
+type TokenRotation struct {
+	// Enabled turns rotation on.
+	Enabled bool `json:"enabled"`
+
+	// ExpiryDays is the number of days replacement tokens are valid for.
+	// +optional
+	// +kubebuilder:default=90
+	// +kubebuilder:validation:Minimum=1
+	ExpiryDays int `json:"expiryDays,omitempty"`
+
+	// RotateBefore is how long before its expiry a token is replaced. It must
+	// be shorter than ExpiryDays, or replacement tokens would be due as soon
+	// as they are created.
+	// +optional
+	// +kubebuilder:default="168h"
+	RotateBefore *metav1.Duration `json:"rotateBefore,omitempty"`
+
+	// Permissions granted to replacement tokens.
+	// +optional
+	// +kubebuilder:default={"PROJECT_ADMIN","REPO_ADMIN"}
+	Permissions []string `json:"permissions,omitempty"`
+
+	// TokenID is the ID of the token the Secret holds when rotation is
+	// enabled, which tells the provider when that token expires and allows
+	// it to revoke the token once replaced. Without it the token is replaced
+	// right away and has to be revoked by hand.
+	// +optional
+	TokenID string `json:"tokenID,omitempty"`
+}
+
+// RateLimit configures a token bucket that bounds the rate of requests.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int `json:"requestsPerSecond"`
+
+	// Burst is how many requests may be sent at once. It defaults to
+	// RequestsPerSecond.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst int `json:"burst,omitempty"`
+}
+
+// RetryPolicy configures how requests that failed transiently are retried,
+// waiting a jittered, exponentially growing backoff between the attempts.
+type RetryPolicy struct {
+	// MaxRetries is how often a request is retried, zero disabling retries.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// MinBackoff is the wait before the first retry, which doubles with every
+	// further retry.
+	// +optional
+	// +kubebuilder:default="500ms"
+	MinBackoff *metav1.Duration `json:"minBackoff,omitempty"`
+
+	// MaxBackoff caps the wait between retries. Requests the server asks to
+	// retry even later through Retry-After are not retried.
+	// +optional
+	// +kubebuilder:default="10s"
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// ProxyConfig configures the HTTP(S) proxy requests to the server go through.
+type ProxyConfig struct {
+	// URL of the proxy, e.g. http://proxy.example.org:3128.
+	URL string `json:"url"`
+
+	// NoProxy lists the hosts requests go to directly, in the format of the
+	// NO_PROXY environment variable, e.g. .example.org or 10.0.0.0/8.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// TLSConfig configures how the connection to the server is secured.
+type TLSConfig struct {
+	// CABundle holds the PEM encoded certificates of the certificate
+	// authorities the certificate of the server is verified against, in
+	// addition to the system roots.
+	// +optional
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+
+	// ClientCertificate is presented to the server for mutual TLS.
+	// +optional
+	ClientCertificate *ClientCertificate `json:"clientCertificate,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificate of the
+	// server. It leaves the connection open to interception, so only use it
+	// for testing.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CABundleSource selects the key of a Secret or ConfigMap holding a CA bundle.
+// +kubebuilder:validation:XValidation:rule="has(self.secretRef) != has(self.configMapRef)",message="exactly one of secretRef or configMapRef must be set"
+type CABundleSource struct {
+	// SecretRef selects the key of a Secret holding the CA bundle.
+	// +optional
+	SecretRef *xpv1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// ConfigMapRef selects the key of a ConfigMap holding the CA bundle.
+	// +optional
+	ConfigMapRef *ConfigMapKeySelector `json:"configMapRef,omitempty"`
+}
+
+// ConfigMapKeySelector selects a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	Namespace string `json:"namespace"`
+
+	// Key of the ConfigMap to select.
+	Key string `json:"key"`
+}
+
+// ClientCertificate selects the PEM encoded certificate and private key the
+// provider presents to the server.
+type ClientCertificate struct {
+	// CertSecretRef selects the key of a Secret holding the certificate.
+	CertSecretRef xpv1.SecretKeySelector `json:"certSecretRef"`
+
+	// KeySecretRef selects the key of a Secret holding the private key.
+	KeySecretRef xpv1.SecretKeySelector `json:"keySecretRef"`
+}
+
+// AuthType is a way to authenticate against bitbucket.
+// +kubebuilder:validation:Enum=Basic;Bearer
+type AuthType string
+
+// Supported ways to authenticate.
+const (
+	AuthTypeBasic  AuthType = "Basic"
+	AuthTypeBearer AuthType = "Bearer"
+)
+
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
@@ -45,12 +227,52 @@ type ProviderCredentials struct {
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// Rotation reports the state of the rotation of the access token.
+	// +optional
+	Rotation *TokenRotationStatus `json:"rotation,omitempty"`
+
+	// Server reports the bitbucket server the ProviderConfig was last
+	// successfully checked against.
+	// +optional
+	Server *ServerStatus `json:"server,omitempty"`
+}
+
+// TokenRotationStatus is the observed state of the rotation of the access
+// token the provider authenticates with.
+type TokenRotationStatus struct {
+	// TokenID is the ID of the token the Secret holds.
+	TokenID string `json:"tokenID,omitempty"`
+
+	// ExpiryDate is when the token expires. It is unset for tokens that do
+	// not expire, or whose expiry is not known.
+	ExpiryDate *metav1.Time `json:"expiryDate,omitempty"`
+
+	// NextRotationTime is when the token will be replaced.
+	NextRotationTime *metav1.Time `json:"nextRotationTime,omitempty"`
+
+	// LastRotationTime is when the token was last replaced.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// ServerStatus is the observed state of the bitbucket server.
+type ServerStatus struct {
+	// Version of the server.
+	Version string `json:"version,omitempty"`
+
+	// BuildNumber of the server.
+	BuildNumber string `json:"buildNumber,omitempty"`
+
+	// Username is the user the provider is authenticated as.
+	Username string `json:"username,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 
 // A ProviderConfig configures a BitbucketServer provider.
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.server.version"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:resource:scope=Cluster
@@ -0,0 +1,139 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// AccessKeyService provides operations around access keys, SSH keys that
+// grant access to the repositories of a project or to a single repository
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-ssh/
+type AccessKeyService interface {
+	GetAccessKey(context.Context, *GetAccessKeyRequest) (*AccessKey, error)
+	CreateAccessKey(context.Context, *CreateAccessKeyRequest) (*AccessKey, error)
+	UpdateAccessKeyPermission(context.Context, *UpdateAccessKeyPermissionRequest) (*AccessKey, error)
+	DeleteAccessKey(context.Context, *DeleteAccessKeyRequest) error
+}
+
+type accessKeyService struct {
+	service
+}
+
+// AccessKey represents an SSH key granted access to a project or repository
+type AccessKey struct {
+	Key        SSHKey `json:"key"`
+	Permission string `json:"permission"`
+}
+
+// SSHKey represents a public SSH key
+type SSHKey struct {
+	ID    int    `json:"id,omitempty"`
+	Text  string `json:"text"`
+	Label string `json:"label,omitempty"`
+}
+
+// GetAccessKeyRequest contains the fields required to fetch an access key
+type GetAccessKeyRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project access keys
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (as *accessKeyService) GetAccessKey(ctx context.Context, getReq *GetAccessKeyRequest) (*AccessKey, error) {
+	req, err := as.newRequest("GET", fmt.Sprintf("%s/%d", accessKeysPath(getReq.ProjectKey, getReq.RepositorySlug), getReq.ID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting access key: %w", err)
+	}
+
+	k := AccessKey{}
+	err = as.client.do(ctx, req, &k)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching access key: %w", err)
+	}
+	return &k, nil
+}
+
+// CreateAccessKeyRequest contains the fields required to create an access key
+type CreateAccessKeyRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project access keys
+	RepositorySlug string `json:"-"`
+	Key            SSHKey `json:"key"`
+	// Permission is one of PROJECT_READ or PROJECT_WRITE for project access
+	// keys and REPO_READ or REPO_WRITE for repository access keys
+	Permission string `json:"permission"`
+}
+
+func (as *accessKeyService) CreateAccessKey(ctx context.Context, createReq *CreateAccessKeyRequest) (*AccessKey, error) {
+	req, err := as.newRequest("POST", accessKeysPath(createReq.ProjectKey, createReq.RepositorySlug), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating access key: %w", err)
+	}
+
+	k := AccessKey{}
+	err = as.client.do(ctx, req, &k)
+	if err != nil {
+		return nil, fmt.Errorf("error creating access key: %w", err)
+	}
+
+	return &k, nil
+}
+
+// UpdateAccessKeyPermissionRequest contains the fields required to change the
+// permission of an access key
+type UpdateAccessKeyPermissionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project access keys
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+	Permission     string `json:"-"`
+}
+
+func (as *accessKeyService) UpdateAccessKeyPermission(ctx context.Context, updateReq *UpdateAccessKeyPermissionRequest) (*AccessKey, error) {
+	path := fmt.Sprintf("%s/%d/permission/%s", accessKeysPath(updateReq.ProjectKey, updateReq.RepositorySlug), updateReq.ID, updateReq.Permission)
+	req, err := as.newRequest("PUT", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for updating access key permission: %w", err)
+	}
+
+	k := AccessKey{}
+	err = as.client.do(ctx, req, &k)
+	if err != nil {
+		return nil, fmt.Errorf("error updating access key permission: %w", err)
+	}
+
+	return &k, nil
+}
+
+// DeleteAccessKeyRequest contains the fields required to delete an access key
+type DeleteAccessKeyRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project access keys
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (as *accessKeyService) DeleteAccessKey(ctx context.Context, deleteReq *DeleteAccessKeyRequest) error {
+	req, err := as.newRequest("DELETE", fmt.Sprintf("%s/%d", accessKeysPath(deleteReq.ProjectKey, deleteReq.RepositorySlug), deleteReq.ID), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting access key: %w", err)
+	}
+
+	err = as.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting access key: %w", err)
+	}
+
+	return nil
+}
+
+// accessKeysPath builds the path of the access keys of a project, or of a
+// repository when slug is set
+func accessKeysPath(projectKey string, slug string) string {
+	if slug == "" {
+		return fmt.Sprintf("projects/%s/ssh", url.PathEscape(projectKey))
+	}
+	return fmt.Sprintf("projects/%s/repos/%s/ssh", url.PathEscape(projectKey), url.PathEscape(slug))
+}
@@ -0,0 +1,149 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// AccessTokenService provides operations around HTTP access tokens, which are
+// scoped to a project, a repository or a user
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-authentication/
+type AccessTokenService interface {
+	GetAccessToken(context.Context, *GetAccessTokenRequest) (*AccessToken, error)
+	CreateAccessToken(context.Context, *CreateAccessTokenRequest) (*AccessToken, error)
+	UpdateAccessToken(context.Context, *UpdateAccessTokenRequest) (*AccessToken, error)
+	DeleteAccessToken(context.Context, *DeleteAccessTokenRequest) error
+}
+
+type accessTokenService struct {
+	service
+}
+
+// AccessToken represents an HTTP access token
+type AccessToken struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	// CreatedDate is in milliseconds since the epoch
+	CreatedDate int64 `json:"createdDate"`
+	// ExpiryDate is in milliseconds since the epoch, and zero for tokens that
+	// do not expire
+	ExpiryDate int64 `json:"expiryDate,omitempty"`
+	// Token is the secret value of the token, only returned on creation
+	Token string `json:"token,omitempty"`
+	User  User   `json:"user"`
+}
+
+// TokenScope selects what an access token grants access to. Exactly one of
+// User or ProjectKey has to be set, RepositorySlug narrows a project scope
+// down to one of its repositories.
+type TokenScope struct {
+	ProjectKey     string `json:"-"`
+	RepositorySlug string `json:"-"`
+	User           string `json:"-"`
+}
+
+// path builds the path of the access tokens of the scope
+func (s TokenScope) path() string {
+	switch {
+	case s.User != "":
+		return fmt.Sprintf("users/%s", url.PathEscape(s.User))
+	case s.RepositorySlug != "":
+		return fmt.Sprintf("repositories/%s/%s", url.PathEscape(s.ProjectKey), url.PathEscape(s.RepositorySlug))
+	}
+	return fmt.Sprintf("projects/%s", url.PathEscape(s.ProjectKey))
+}
+
+// GetAccessTokenRequest contains the fields required to fetch an access token
+type GetAccessTokenRequest struct {
+	TokenScope
+	ID string `json:"-"`
+}
+
+func (ts *accessTokenService) GetAccessToken(ctx context.Context, getReq *GetAccessTokenRequest) (*AccessToken, error) {
+	req, err := ts.newRequest("GET", fmt.Sprintf("%s/%s", getReq.path(), url.PathEscape(getReq.ID)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting access token: %w", err)
+	}
+
+	t := AccessToken{}
+	err = ts.client.do(ctx, req, &t)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching access token: %w", err)
+	}
+	return &t, nil
+}
+
+// CreateAccessTokenRequest contains the fields required to create an access
+// token
+type CreateAccessTokenRequest struct {
+	TokenScope
+	Name string `json:"name"`
+	// Permissions granted to the token, e.g. PROJECT_READ or REPO_WRITE
+	Permissions []string `json:"permissions"`
+	// ExpiryDays is the number of days until the token expires, the server
+	// default applies when zero
+	ExpiryDays int `json:"expiryDays,omitempty"`
+}
+
+func (ts *accessTokenService) CreateAccessToken(ctx context.Context, createReq *CreateAccessTokenRequest) (*AccessToken, error) {
+	req, err := ts.newRequest("PUT", createReq.path(), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating access token: %w", err)
+	}
+
+	// every attempt would mint another token
+	t := AccessToken{}
+	err = ts.client.do(withoutRetry(ctx), req, &t)
+	if err != nil {
+		return nil, fmt.Errorf("error creating access token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// UpdateAccessTokenRequest contains the fields required to update an access
+// token
+type UpdateAccessTokenRequest struct {
+	TokenScope
+	ID          string   `json:"-"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+func (ts *accessTokenService) UpdateAccessToken(ctx context.Context, updateReq *UpdateAccessTokenRequest) (*AccessToken, error) {
+	req, err := ts.newRequest("POST", fmt.Sprintf("%s/%s", updateReq.path(), url.PathEscape(updateReq.ID)), updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for updating access token: %w", err)
+	}
+
+	t := AccessToken{}
+	err = ts.client.do(ctx, req, &t)
+	if err != nil {
+		return nil, fmt.Errorf("error updating access token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// DeleteAccessTokenRequest contains the fields required to delete an access
+// token
+type DeleteAccessTokenRequest struct {
+	TokenScope
+	ID string `json:"-"`
+}
+
+func (ts *accessTokenService) DeleteAccessToken(ctx context.Context, deleteReq *DeleteAccessTokenRequest) error {
+	req, err := ts.newRequest("DELETE", fmt.Sprintf("%s/%s", deleteReq.path(), url.PathEscape(deleteReq.ID)), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting access token: %w", err)
+	}
+
+	err = ts.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting access token: %w", err)
+	}
+
+	return nil
+}
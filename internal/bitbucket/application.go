@@ -0,0 +1,51 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ApplicationService provides information about the bitbucket server itself
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-system-maintenance/#api-api-latest-application-properties-get
+type ApplicationService interface {
+	GetApplicationProperties(context.Context) (*ApplicationProperties, error)
+}
+
+type applicationService struct {
+	service
+}
+
+// ApplicationProperties describes the bitbucket server
+type ApplicationProperties struct {
+	Version     string `json:"version"`
+	BuildNumber string `json:"buildNumber"`
+	BuildDate   string `json:"buildDate"`
+	DisplayName string `json:"displayName"`
+
+	// Username is the user the server authenticated the request as, it is
+	// empty when the request was served anonymously
+	Username string `json:"-"`
+}
+
+// readHeader reads the authenticated user from the header the server adds to
+// its responses
+func (p *ApplicationProperties) readHeader(h http.Header) {
+	p.Username = h.Get("X-AUSERNAME")
+}
+
+// GetApplicationProperties returns the version and build of the server,
+// along with the user the client is authenticated as.
+func (as *applicationService) GetApplicationProperties(ctx context.Context) (*ApplicationProperties, error) {
+	req, err := as.newRequest("GET", "application-properties", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting application properties: %w", err)
+	}
+
+	p := ApplicationProperties{}
+	err = as.client.do(ctx, req, &p)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching application properties: %w", err)
+	}
+	return &p, nil
+}
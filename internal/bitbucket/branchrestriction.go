@@ -0,0 +1,157 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Restriction types supported by the branch permissions api
+const (
+	RestrictionReadOnly        = "read-only"
+	RestrictionNoDeletes       = "no-deletes"
+	RestrictionFastForwardOnly = "fast-forward-only"
+	RestrictionPullRequestOnly = "pull-request-only"
+)
+
+// Matcher types supported by the branch permissions api
+const (
+	MatcherBranch        = "BRANCH"
+	MatcherPattern       = "PATTERN"
+	MatcherModelCategory = "MODEL_CATEGORY"
+	MatcherModelBranch   = "MODEL_BRANCH"
+)
+
+// BranchRestrictionService provides operations around branch restrictions,
+// which bitbucket calls branch permissions
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-permission-management/
+type BranchRestrictionService interface {
+	GetBranchRestriction(context.Context, *GetBranchRestrictionRequest) (*BranchRestriction, error)
+	CreateBranchRestriction(context.Context, *CreateBranchRestrictionRequest) (*BranchRestriction, error)
+	DeleteBranchRestriction(context.Context, *DeleteBranchRestrictionRequest) error
+}
+
+type branchRestrictionService struct {
+	service
+}
+
+// BranchRestriction represents a restriction on the refs of a project or
+// repository
+type BranchRestriction struct {
+	ID         int                    `json:"id"`
+	Type       string                 `json:"type"`
+	Matcher    RefMatcher             `json:"matcher"`
+	Users      []User                 `json:"users"`
+	Groups     []string               `json:"groups"`
+	AccessKeys []RestrictionAccessKey `json:"accessKeys"`
+}
+
+// RefMatcher selects the refs a branch restriction applies to
+type RefMatcher struct {
+	ID        string         `json:"id"`
+	DisplayID string         `json:"displayId"`
+	Type      RefMatcherType `json:"type"`
+	Active    bool           `json:"active"`
+}
+
+// RefMatcherType is the kind of a RefMatcher, e.g. BRANCH or PATTERN
+type RefMatcherType struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// RestrictionAccessKey is an access key exempted from a branch restriction
+type RestrictionAccessKey struct {
+	Key struct {
+		ID int `json:"id"`
+	} `json:"key"`
+}
+
+// GetBranchRestrictionRequest contains the fields required to fetch a branch
+// restriction
+type GetBranchRestrictionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for restrictions that apply to a whole project
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (bs *branchRestrictionService) GetBranchRestriction(ctx context.Context, getReq *GetBranchRestrictionRequest) (*BranchRestriction, error) {
+	path := fmt.Sprintf("%s/%d", restrictionsPath(getReq.ProjectKey, getReq.RepositorySlug), getReq.ID)
+	req, err := bs.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting branch restriction: %w", err)
+	}
+
+	r := BranchRestriction{}
+	err = bs.client.do(ctx, req, &r)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching branch restriction: %w", err)
+	}
+	return &r, nil
+}
+
+// CreateBranchRestrictionRequest contains the fields required to create a
+// branch restriction. Bitbucket replaces an existing restriction of the same
+// type and matcher instead of creating a second one.
+type CreateBranchRestrictionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for restrictions that apply to a whole project
+	RepositorySlug string     `json:"-"`
+	Type           string     `json:"type"`
+	Matcher        RefMatcher `json:"matcher"`
+	// Users are the names of the users exempted from the restriction
+	Users []string `json:"users"`
+	// Groups are the names of the groups exempted from the restriction
+	Groups []string `json:"groups"`
+	// AccessKeys are the IDs of the access keys exempted from the restriction
+	AccessKeys []int `json:"accessKeys"`
+}
+
+func (bs *branchRestrictionService) CreateBranchRestriction(ctx context.Context, createReq *CreateBranchRestrictionRequest) (*BranchRestriction, error) {
+	req, err := bs.newRequest("POST", restrictionsPath(createReq.ProjectKey, createReq.RepositorySlug), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating branch restriction: %w", err)
+	}
+
+	r := BranchRestriction{}
+	err = bs.client.do(ctx, req, &r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating branch restriction: %w", err)
+	}
+
+	return &r, nil
+}
+
+// DeleteBranchRestrictionRequest contains the fields required to delete a
+// branch restriction
+type DeleteBranchRestrictionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for restrictions that apply to a whole project
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (bs *branchRestrictionService) DeleteBranchRestriction(ctx context.Context, deleteReq *DeleteBranchRestrictionRequest) error {
+	path := fmt.Sprintf("%s/%d", restrictionsPath(deleteReq.ProjectKey, deleteReq.RepositorySlug), deleteReq.ID)
+	req, err := bs.newRequest("DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting branch restriction: %w", err)
+	}
+
+	err = bs.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting branch restriction: %w", err)
+	}
+
+	return nil
+}
+
+// restrictionsPath builds the path of the restrictions of a project, or of a
+// repository when slug is set
+func restrictionsPath(projectKey string, slug string) string {
+	if slug == "" {
+		return fmt.Sprintf("projects/%s/restrictions", url.PathEscape(projectKey))
+	}
+	return fmt.Sprintf("projects/%s/repos/%s/restrictions", url.PathEscape(projectKey), url.PathEscape(slug))
+}
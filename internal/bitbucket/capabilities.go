@@ -0,0 +1,126 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported represents requests for features the server does not
+// support in its version
+var ErrUnsupported = errors.New("unsupported")
+
+// Feature is an api of bitbucket server that is not available in every
+// version of it
+type Feature string
+
+// Features whose support depends on the version of the server
+const (
+	FeatureAccessTokens         Feature = "access tokens"
+	FeatureArchivedRepositories Feature = "archived repositories"
+	FeatureProjectWebhooks      Feature = "project webhooks"
+	FeatureSecretScanning       Feature = "secret scanning"
+)
+
+// minVersions are the versions of the server that introduced each feature
+var minVersions = map[Feature]version{
+	FeatureAccessTokens:         {5, 5, 0},
+	FeatureArchivedRepositories: {8, 0, 0},
+	FeatureProjectWebhooks:      {7, 13, 0},
+	FeatureSecretScanning:       {8, 3, 0},
+}
+
+// version is the major, minor and patch number of a server version
+type version [3]int
+
+// parseVersion parses the leading numbers of versions such as 8.5.0 or
+// 7.21.0-SNAPSHOT
+func parseVersion(s string) (version, bool) {
+	v := version{}
+	parts := strings.SplitN(s, ".", len(v))
+	for i, p := range parts {
+		if i == len(parts)-1 {
+			// drop qualifiers such as -SNAPSHOT
+			if end := strings.IndexFunc(p, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+				p = p[:end]
+			}
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return version{}, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func (v version) less(o version) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// Capabilities tells which features the server supports
+type Capabilities struct {
+	version string
+}
+
+// Version returns the version of the server
+func (c *Capabilities) Version() string {
+	return c.version
+}
+
+// Supports reports whether the server supports feature f. Servers whose
+// version cannot be parsed are assumed to support every feature, leaving it
+// to them to reject what they do not support.
+func (c *Capabilities) Supports(f Feature) bool {
+	v, ok := parseVersion(c.version)
+	if !ok {
+		return true
+	}
+	return !v.less(minVersions[f])
+}
+
+// Require returns an error matching ErrUnsupported when the server does not
+// support feature f.
+func (c *Capabilities) Require(f Feature) error {
+	if c.Supports(f) {
+		return nil
+	}
+	return fmt.Errorf("%s are %w by server version %s, they require version %s or later", f, ErrUnsupported, c.version, minVersions[f])
+}
+
+// Capabilities returns the features the server supports. The version of the
+// server is read the first time, and remembered for the lifetime of the client.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	c.capsMu.Lock()
+	caps := c.caps
+	c.capsMu.Unlock()
+	if caps != nil {
+		return caps, nil
+	}
+
+	// the lock is not held while the version is read, so that a slow server
+	// does not block every caller, at the cost of concurrent callers reading
+	// it more than once
+	p, err := c.Application.GetApplicationProperties(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error detecting server version: %w", err)
+	}
+
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+	if c.caps == nil {
+		c.caps = &Capabilities{version: p.Version}
+	}
+	return c.caps, nil
+}
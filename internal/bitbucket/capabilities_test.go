@@ -0,0 +1,193 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// applicationFn serves application properties from a function
+type applicationFn func(context.Context) (*ApplicationProperties, error)
+
+func (fn applicationFn) GetApplicationProperties(ctx context.Context) (*ApplicationProperties, error) {
+	return fn(ctx)
+}
+
+func TestParseVersion(t *testing.T) {
+	type want struct {
+		v  version
+		ok bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"Release": {
+			reason: "We should parse the major, minor and patch number of a release.",
+			s:      "8.5.0",
+			want:   want{v: version{8, 5, 0}, ok: true},
+		},
+		"Snapshot": {
+			reason: "We should drop the qualifier of a snapshot.",
+			s:      "7.21.0-SNAPSHOT",
+			want:   want{v: version{7, 21, 0}, ok: true},
+		},
+		"MajorMinor": {
+			reason: "We should treat a missing patch number as zero.",
+			s:      "8.9",
+			want:   want{v: version{8, 9, 0}, ok: true},
+		},
+		"Build": {
+			reason: "We should ignore what follows the patch number.",
+			s:      "8.5.0.1",
+			want:   want{v: version{8, 5, 0}, ok: true},
+		},
+		"Empty": {
+			reason: "We should not parse an empty version.",
+			s:      "",
+			want:   want{ok: false},
+		},
+		"NotANumber": {
+			reason: "We should not parse a version that is not numeric.",
+			s:      "eight.five",
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, ok := parseVersion(tc.s)
+			if diff := cmp.Diff(tc.want, want{v: v, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nparseVersion(%q): -want, +got:\n%s\n", tc.reason, tc.s, diff)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		version string
+		feature Feature
+		want    error
+	}{
+		"Supported": {
+			reason:  "We should support a feature of a server newer than the one that introduced it.",
+			version: "8.5.0",
+			feature: FeatureProjectWebhooks,
+		},
+		"IntroducedIn": {
+			reason:  "We should support a feature of the server version that introduced it.",
+			version: "7.13.0",
+			feature: FeatureProjectWebhooks,
+		},
+		"Unsupported": {
+			reason:  "We should not support a feature of a server older than the one that introduced it.",
+			version: "7.12.1",
+			feature: FeatureProjectWebhooks,
+			want:    ErrUnsupported,
+		},
+		"UnsupportedMajor": {
+			reason:  "We should compare the major version before the minor version.",
+			version: "7.21.0",
+			feature: FeatureArchivedRepositories,
+			want:    ErrUnsupported,
+		},
+		"UnknownVersion": {
+			reason:  "We should leave it to a server whose version cannot be parsed to reject what it does not support.",
+			version: "unknown",
+			feature: FeatureSecretScanning,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &Capabilities{version: tc.version}
+			err := c.Require(tc.feature)
+			if !errors.Is(err, tc.want) || (tc.want == nil) != (err == nil) {
+				t.Errorf("\n%s\nc.Require(%q): want error %v, got %v", tc.reason, tc.feature, tc.want, err)
+			}
+			if got := c.Supports(tc.feature); got != (tc.want == nil) {
+				t.Errorf("\n%s\nc.Supports(%q): want %t, got %t", tc.reason, tc.feature, tc.want == nil, got)
+			}
+		})
+	}
+}
+
+func TestRequireMessage(t *testing.T) {
+	err := (&Capabilities{version: "5.4.0"}).Require(FeatureAccessTokens)
+	want := "access tokens are unsupported by server version 5.4.0, they require version 5.5.0 or later"
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Errorf("We should tell which version the feature requires.\n-want, +got:\n%s\n", diff)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	calls := 0
+	c := &Client{Application: applicationFn(func(context.Context) (*ApplicationProperties, error) {
+		calls++
+		if calls == 1 {
+			return nil, ErrServer
+		}
+		return &ApplicationProperties{Version: "8.5.0"}, nil
+	})}
+
+	if _, err := c.Capabilities(context.Background()); !errors.Is(err, ErrServer) {
+		t.Errorf("c.Capabilities(...): want error %v, got %v", ErrServer, err)
+	}
+	for i := 0; i < 2; i++ {
+		caps, err := c.Capabilities(context.Background())
+		if err != nil {
+			t.Fatalf("c.Capabilities(...): unexpected error: %s", err)
+		}
+		if diff := cmp.Diff("8.5.0", caps.Version()); diff != "" {
+			t.Errorf("c.Capabilities(...): -want version, +got version:\n%s\n", diff)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("We should read the version again after failing to, then remember it, want 2 reads, got %d", calls)
+	}
+}
+
+func TestCapabilitiesNotBlocked(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	c := &Client{Application: applicationFn(func(ctx context.Context) (*ApplicationProperties, error) {
+		first := false
+		once.Do(func() { first = true })
+		if !first {
+			return nil, ErrServer
+		}
+		close(started)
+		<-release
+		return &ApplicationProperties{Version: "8.5.0"}, nil
+	})}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = c.Capabilities(context.Background())
+	}()
+	<-started
+
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		_, _ = c.Capabilities(context.Background())
+	}()
+
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Error("We should not block callers while another one reads the version of a slow server.")
+	}
+	close(release)
+	<-done
+	<-blocked
+}
@@ -9,12 +9,35 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
-const (
-	apiPath       = "/rest/api/1.0/"
-	jsonMediaType = "application/json"
+const jsonMediaType = "application/json"
+
+// restAPI identifies one of the REST apis of bitbucket server, which are each
+// served by a plugin under /rest/{plugin}/{version}/
+type restAPI struct {
+	plugin  string
+	version string
+}
+
+// path returns the path of the api relative to the server root
+func (a restAPI) path() string {
+	return fmt.Sprintf("/rest/%s/%s/", a.plugin, a.version)
+}
+
+// REST apis the services of the client talk to
+var (
+	coreAPI              = restAPI{plugin: "api", version: "1.0"}
+	branchPermissionsAPI = restAPI{plugin: "branch-permissions", version: "2.0"}
+	defaultReviewersAPI  = restAPI{plugin: "default-reviewers", version: "1.0"}
+	accessTokensAPI      = restAPI{plugin: "access-tokens", version: "1.0"}
+	keysAPI              = restAPI{plugin: "keys", version: "1.0"}
+	branchUtilsAPI       = restAPI{plugin: "branch-utils", version: "1.0"}
 )
 
 // Client encapsulates a client that talks to the bitbucket server api
@@ -26,10 +49,34 @@ type Client struct {
 	// headers are used to override request headers for every single HTTP request
 	headers map[string]string
 
-	// base URL for the bitbucket server + apiPath
+	// root URL of the bitbucket server, the REST api of each service is
+	// resolved against it
 	baseURL *url.URL
 
-	Projects ProjectService
+	// pageSize is the number of values requested per page of a paged api
+	pageSize int
+
+	// retry is how requests that failed transiently are retried
+	retry RetryPolicy
+
+	// limiter bounds the rate of requests to the server, it is nil when
+	// requests are not limited
+	limiter *rate.Limiter
+
+	// caps are the features the server supports, remembered by Capabilities
+	capsMu sync.Mutex
+	caps   *Capabilities
+
+	Projects           ProjectService
+	Repositories       RepositoryService
+	Permissions        PermissionService
+	BranchRestrictions BranchRestrictionService
+	Webhooks           WebhookService
+	DefaultReviewers   DefaultReviewerService
+	Users              UserService
+	AccessKeys         AccessKeyService
+	AccessTokens       AccessTokenService
+	Application        ApplicationService
 }
 
 var (
@@ -41,47 +88,107 @@ var (
 	ErrResponseMalformed = errors.New("response_malformed")
 	// ErrConflict is used when a duplicate resource is trying to be created
 	ErrConflict = errors.New("conflict")
+	// ErrForbidden represents requests the authenticated user is not allowed to make
+	ErrForbidden = errors.New("forbidden")
+	// ErrBadRequest represents requests the server rejected as invalid
+	ErrBadRequest = errors.New("bad_request")
+	// ErrServer represents errors of the server while handling a request
+	ErrServer = errors.New("server_error")
 )
 
-// NewClient creates a new instance of the bitbucket client
-func NewClient(baseURL string, base64creds string) (*Client, error) {
-	pBaseURL, err := url.Parse(fmt.Sprintf("%s%s", baseURL, apiPath))
+// NewClient creates a new instance of the bitbucket client that authenticates
+// with basic auth, base64creds being the base64 encoding of username:password
+func NewClient(baseURL string, base64creds string, opts ...Option) (*Client, error) {
+	return New(context.Background(), baseURL, append([]Option{WithAuth(AuthBasic, base64creds)}, opts...)...)
+}
+
+// New creates a new instance of the bitbucket client configured by opts. It
+// checks that the server can be reached unless WithoutPing is given.
+func New(ctx context.Context, baseURL string, opts ...Option) (*Client, error) {
+	pBaseURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	c := &Client{
-		baseURL: pBaseURL,
-		client:  &http.Client{Timeout: time.Second * 10},
-		headers: map[string]string{"Authorization": "Basic " + base64creds},
+	s := &settings{headers: map[string]string{}, pageSize: defaultPageSize, retry: DefaultRetryPolicy}
+	for _, o := range opts {
+		o(s)
 	}
 
-	err = c.ping()
+	hc, err := s.buildHTTPClient()
 	if err != nil {
-		return nil, fmt.Errorf("error creating bitbucket client: %w", err)
+		return nil, err
 	}
 
-	c.Projects = &projectService{client: c}
+	c := &Client{
+		baseURL:  pBaseURL,
+		client:   hc,
+		headers:  s.headers,
+		pageSize: s.pageSize,
+		retry:    s.retry,
+	}
+	if s.authorization != "" {
+		c.headers["Authorization"] = s.authorization
+	}
+	if s.userAgent != "" {
+		c.headers["User-Agent"] = s.userAgent
+	}
+	if s.rateLimit > 0 {
+		c.limiter = sharedLimiter(pBaseURL, s.rateLimit, s.burst)
+	}
+
+	if !s.skipPing {
+		err = c.ping(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error creating bitbucket client: %w", err)
+		}
+	}
+
+	c.Projects = &projectService{service{client: c, api: coreAPI}}
+	c.Repositories = &repositoryService{service{client: c, api: coreAPI}}
+	c.Permissions = &permissionService{service{client: c, api: coreAPI}}
+	c.BranchRestrictions = &branchRestrictionService{service{client: c, api: branchPermissionsAPI}}
+	c.Webhooks = &webhookService{service{client: c, api: coreAPI}}
+	c.DefaultReviewers = &defaultReviewerService{service{client: c, api: defaultReviewersAPI}}
+	c.Users = &userService{service{client: c, api: coreAPI}}
+	c.AccessKeys = &accessKeyService{service{client: c, api: keysAPI}}
+	c.AccessTokens = &accessTokenService{service{client: c, api: accessTokensAPI}}
+	c.Application = &applicationService{service{client: c, api: coreAPI}}
 
 	return c, nil
 }
 
 // ping is used to check that the client can correctly communicate with the bitbucket api
-func (c *Client) ping() error {
-	req, err := c.newRequest("GET", "projects", nil)
-	if err != nil {
-		return fmt.Errorf("error creating request for getting projects: %w", err)
-	}
-
-	err = c.do(context.Background(), req, nil)
-	if err != nil {
+func (c *Client) ping(ctx context.Context) error {
+	it := newPageIterator[Project](&service{client: c, api: coreAPI}, "projects").WithPageSize(1)
+	it.Next(ctx)
+	if err := it.Err(); err != nil {
 		return fmt.Errorf("error fetching projects: %w", err)
 	}
 	return nil
 }
 
-func (c *Client) newRequest(method string, path string, body interface{}) (*http.Request, error) {
-	u, err := c.baseURL.Parse(path)
+// service holds what every service needs to talk to its REST api
+type service struct {
+	client *Client
+
+	// api is the REST api the service talks to
+	api restAPI
+}
+
+// newRequest creates a request against the REST api of the service
+func (s *service) newRequest(method string, path string, body interface{}) (*http.Request, error) {
+	return s.client.newRequest(s.api, method, path, body)
+}
+
+// newRequest creates a request for path, relative to the given REST api
+func (c *Client) newRequest(api restAPI, method string, path string, body interface{}) (*http.Request, error) {
+	base, err := c.baseURL.Parse(strings.TrimSuffix(c.baseURL.Path, "/") + api.path())
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := base.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -120,14 +227,41 @@ func (c *Client) newRequest(method string, path string, body interface{}) (*http
 }
 
 // do makes an HTTP request and populates the given struct v from the response.
+// Idempotent requests that failed transiently are retried according to the
+// retry policy of the client.
 func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) error {
 	req = req.WithContext(ctx)
-	res, err := c.client.Do(req)
-	if err != nil {
-		return err
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		res, err := c.client.Do(req)
+		wait, retry := c.retry.retryWait(req, res, err, attempt)
+		if !retry {
+			if err != nil {
+				return err
+			}
+			defer res.Body.Close()
+			return c.handleResponse(res, v)
+		}
+		discard(res)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		req, err = rewind(req)
+		if err != nil {
+			return err
+		}
 	}
-	defer res.Body.Close()
-	return c.handleResponse(res, v)
 }
 
 // handleResponse makes an HTTP request and populates the given struct v from
@@ -139,13 +273,8 @@ func (c *Client) handleResponse(res *http.Response, v interface{}) error {
 		return err
 	}
 
-	switch res.StatusCode {
-	case 404:
-		return ErrNotFound
-	case 401:
-		return ErrPermission
-	case 409:
-		return ErrConflict
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newAPIError(res, out)
 	}
 
 	// this means we don't care about unmarshaling the response body into v
@@ -162,5 +291,14 @@ func (c *Client) handleResponse(res *http.Response, v interface{}) error {
 		return err
 	}
 
+	if h, ok := v.(headerReader); ok {
+		h.readHeader(res.Header)
+	}
 	return nil
 }
+
+// headerReader is implemented by results that are read from the headers of
+// the response as well as from its body
+type headerReader interface {
+	readHeader(http.Header)
+}
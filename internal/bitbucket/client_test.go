@@ -0,0 +1,136 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestClient returns a client of the server at srv that does not ping it
+// and does not wait between retries
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{WithoutPing(), WithRetryPolicy(RetryPolicy{})}, opts...)
+	c, err := New(context.Background(), srv.URL, opts...)
+	if err != nil {
+		t.Fatalf("New(...): unexpected error: %s", err)
+	}
+	return c
+}
+
+// writeJSON writes v as the JSON body of a response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", jsonMediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewRequest(t *testing.T) {
+	type args struct {
+		baseURL string
+		api     restAPI
+		path    string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Root": {
+			reason: "We should resolve the path against the api of a server served at its root.",
+			args:   args{baseURL: "https://bitbucket.example.com", api: coreAPI, path: "projects/PRJ"},
+			want:   "https://bitbucket.example.com/rest/api/1.0/projects/PRJ",
+		},
+		"ContextPath": {
+			reason: "We should keep the context path of a server that is not served at its root.",
+			args:   args{baseURL: "https://example.com/bitbucket", api: coreAPI, path: "projects/PRJ"},
+			want:   "https://example.com/bitbucket/rest/api/1.0/projects/PRJ",
+		},
+		"ContextPathTrailingSlash": {
+			reason: "We should not double the slash after a context path ending in one.",
+			args:   args{baseURL: "https://example.com/bitbucket/", api: keysAPI, path: "projects/PRJ/ssh"},
+			want:   "https://example.com/bitbucket/rest/keys/1.0/projects/PRJ/ssh",
+		},
+		"Query": {
+			reason: "We should keep the query of the path.",
+			args:   args{baseURL: "https://example.com/bitbucket", api: coreAPI, path: "users?filter=jdoe"},
+			want:   "https://example.com/bitbucket/rest/api/1.0/users?filter=jdoe",
+		},
+		"EscapedSegment": {
+			reason: "We should keep the escaping of path segments.",
+			args:   args{baseURL: "https://example.com", api: accessTokensAPI, path: TokenScope{User: "jane doe/ops"}.path()},
+			want:   "https://example.com/rest/access-tokens/1.0/users/jane%20doe%2Fops",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(context.Background(), tc.args.baseURL, WithoutPing())
+			if err != nil {
+				t.Fatalf("New(...): unexpected error: %s", err)
+			}
+			req, err := c.newRequest(tc.args.api, http.MethodGet, tc.args.path, nil)
+			if err != nil {
+				t.Fatalf("\n%s\nc.newRequest(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, req.URL.String()); diff != "" {
+				t.Errorf("\n%s\nc.newRequest(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTokenScopePath(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		scope  TokenScope
+		want   string
+	}{
+		"User": {
+			reason: "We should escape usernames holding characters reserved in paths.",
+			scope:  TokenScope{User: "jane doe@example.com"},
+			want:   "users/jane%20doe@example.com",
+		},
+		"Project": {
+			reason: "We should build the path of a project scope.",
+			scope:  TokenScope{ProjectKey: "PRJ"},
+			want:   "projects/PRJ",
+		},
+		"Repository": {
+			reason: "We should build the path of a repository scope.",
+			scope:  TokenScope{ProjectKey: "~JDOE", RepositorySlug: "my-repo"},
+			want:   "repositories/~JDOE/my-repo",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.scope.path()); diff != "" {
+				t.Errorf("\n%s\nscope.path(): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEscapedUserReachesServer(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.EscapedPath()
+		writeJSON(w, http.StatusOK, AccessToken{ID: "1"})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv)
+	_, err := c.AccessTokens.GetAccessToken(context.Background(), &GetAccessTokenRequest{TokenScope: TokenScope{User: "jane doe@example.com"}, ID: "1"})
+	if err != nil {
+		t.Fatalf("GetAccessToken(...): unexpected error: %s", err)
+	}
+	if diff := cmp.Diff("/rest/access-tokens/1.0/users/jane%20doe@example.com/1", got); diff != "" {
+		t.Errorf("We should request the token of the user at its escaped path.\n-want, +got:\n%s\n", diff)
+	}
+}
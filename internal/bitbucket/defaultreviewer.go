@@ -0,0 +1,162 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// MatcherAnyRef matches every ref, its matcher id is always AnyRefMatcherID
+const MatcherAnyRef = "ANY_REF"
+
+// AnyRefMatcherID is the id of the matcher of type MatcherAnyRef
+const AnyRefMatcherID = "ANY_REF_MATCHER_ID"
+
+// DefaultReviewerService provides operations around default reviewer
+// conditions, which add reviewers to the pull requests between matching refs
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-default-reviewers/
+type DefaultReviewerService interface {
+	GetCondition(context.Context, *GetConditionRequest) (*Condition, error)
+	CreateCondition(context.Context, *CreateConditionRequest) (*Condition, error)
+	UpdateCondition(context.Context, *UpdateConditionRequest) (*Condition, error)
+	DeleteCondition(context.Context, *DeleteConditionRequest) error
+}
+
+type defaultReviewerService struct {
+	service
+}
+
+// Condition represents a default reviewer condition
+type Condition struct {
+	ID                int        `json:"id"`
+	SourceMatcher     RefMatcher `json:"sourceRefMatcher"`
+	TargetMatcher     RefMatcher `json:"targetRefMatcher"`
+	Reviewers         []User     `json:"reviewers"`
+	RequiredApprovals int        `json:"requiredApprovals"`
+}
+
+// Reviewer identifies a user to add as reviewer by its ID
+type Reviewer struct {
+	ID int `json:"id"`
+}
+
+// GetConditionRequest contains the fields required to fetch a default
+// reviewer condition
+type GetConditionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for conditions that apply to a whole project
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+// GetCondition looks the condition up in the conditions of the project or
+// repository, as the api can not fetch a single condition
+func (ds *defaultReviewerService) GetCondition(ctx context.Context, getReq *GetConditionRequest) (*Condition, error) {
+	req, err := ds.newRequest("GET", conditionsPath(getReq.ProjectKey, getReq.RepositorySlug, "conditions"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting default reviewer conditions: %w", err)
+	}
+
+	conditions := []Condition{}
+	err = ds.client.do(ctx, req, &conditions)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching default reviewer conditions: %w", err)
+	}
+
+	for i := range conditions {
+		if conditions[i].ID == getReq.ID {
+			return &conditions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("error fetching default reviewer condition: %w", ErrNotFound)
+}
+
+// CreateConditionRequest contains the fields required to create a default
+// reviewer condition
+type CreateConditionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for conditions that apply to a whole project
+	RepositorySlug    string     `json:"-"`
+	SourceMatcher     RefMatcher `json:"sourceMatcher"`
+	TargetMatcher     RefMatcher `json:"targetMatcher"`
+	Reviewers         []Reviewer `json:"reviewers"`
+	RequiredApprovals int        `json:"requiredApprovals"`
+}
+
+func (ds *defaultReviewerService) CreateCondition(ctx context.Context, createReq *CreateConditionRequest) (*Condition, error) {
+	req, err := ds.newRequest("POST", conditionsPath(createReq.ProjectKey, createReq.RepositorySlug, "condition"), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating default reviewer condition: %w", err)
+	}
+
+	c := Condition{}
+	err = ds.client.do(ctx, req, &c)
+	if err != nil {
+		return nil, fmt.Errorf("error creating default reviewer condition: %w", err)
+	}
+
+	return &c, nil
+}
+
+// UpdateConditionRequest contains the fields required to update a default
+// reviewer condition
+type UpdateConditionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for conditions that apply to a whole project
+	RepositorySlug    string     `json:"-"`
+	ID                int        `json:"-"`
+	SourceMatcher     RefMatcher `json:"sourceMatcher"`
+	TargetMatcher     RefMatcher `json:"targetMatcher"`
+	Reviewers         []Reviewer `json:"reviewers"`
+	RequiredApprovals int        `json:"requiredApprovals"`
+}
+
+func (ds *defaultReviewerService) UpdateCondition(ctx context.Context, updateReq *UpdateConditionRequest) (*Condition, error) {
+	path := fmt.Sprintf("%s/%d", conditionsPath(updateReq.ProjectKey, updateReq.RepositorySlug, "condition"), updateReq.ID)
+	req, err := ds.newRequest("PUT", path, updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for updating default reviewer condition: %w", err)
+	}
+
+	c := Condition{}
+	err = ds.client.do(ctx, req, &c)
+	if err != nil {
+		return nil, fmt.Errorf("error updating default reviewer condition: %w", err)
+	}
+
+	return &c, nil
+}
+
+// DeleteConditionRequest contains the fields required to delete a default
+// reviewer condition
+type DeleteConditionRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for conditions that apply to a whole project
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (ds *defaultReviewerService) DeleteCondition(ctx context.Context, deleteReq *DeleteConditionRequest) error {
+	path := fmt.Sprintf("%s/%d", conditionsPath(deleteReq.ProjectKey, deleteReq.RepositorySlug, "condition"), deleteReq.ID)
+	req, err := ds.newRequest("DELETE", path, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting default reviewer condition: %w", err)
+	}
+
+	err = ds.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting default reviewer condition: %w", err)
+	}
+
+	return nil
+}
+
+// conditionsPath builds the path of the default reviewer conditions of a
+// project, or of a repository when slug is set. The api uses "condition" for
+// single conditions and "conditions" for listing them.
+func conditionsPath(projectKey string, slug string, resource string) string {
+	if slug == "" {
+		return fmt.Sprintf("projects/%s/%s", url.PathEscape(projectKey), resource)
+	}
+	return fmt.Sprintf("projects/%s/repos/%s/%s", url.PathEscape(projectKey), url.PathEscape(slug), resource)
+}
@@ -0,0 +1,79 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned for every response of the server with a non-2xx
+// status. It matches the sentinel error of its status with errors.Is.
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+
+	// Method and Path identify the request that failed
+	Method string
+	Path   string
+
+	// Errors are the errors reported by the server in the body of the
+	// response, if any
+	Errors []ErrorDetail `json:"errors"`
+}
+
+// ErrorDetail is one of the errors the server reports in a failed response
+type ErrorDetail struct {
+	Context       string `json:"context"`
+	Message       string `json:"message"`
+	ExceptionName string `json:"exceptionName"`
+}
+
+// newAPIError creates the error of a failed response, body being its content
+func newAPIError(res *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: res.StatusCode}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.Path = res.Request.URL.Path
+	}
+	// the body of errors raised before reaching bitbucket, e.g. by a proxy,
+	// is not json, so the status is all there is to report then
+	_ = json.Unmarshal(body, e)
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	details := make([]string, 0, len(e.Errors))
+	for _, d := range e.Errors {
+		if d.Context != "" {
+			details = append(details, d.Context+": "+d.Message)
+			continue
+		}
+		details = append(details, d.Message)
+	}
+	return msg + ": " + strings.Join(details, "; ")
+}
+
+// Is reports whether target is the sentinel error of the status of e. A 403
+// matches both ErrForbidden and ErrPermission.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrPermission:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAPIError(t *testing.T) {
+	sentinels := []error{ErrBadRequest, ErrPermission, ErrForbidden, ErrNotFound, ErrConflict, ErrServer}
+
+	type want struct {
+		err     *APIError
+		message string
+		is      []error
+	}
+
+	cases := map[string]struct {
+		reason      string
+		status      int
+		contentType string
+		body        string
+		want        want
+	}{
+		"Errors": {
+			reason:      "We should report every error in the body of the response.",
+			status:      http.StatusBadRequest,
+			contentType: jsonMediaType,
+			body:        `{"errors":[{"context":"name","message":"Name is required.","exceptionName":"com.atlassian.bitbucket.validation.ArgumentValidationException"},{"message":"Key is invalid."}]}`,
+			want: want{
+				err: &APIError{StatusCode: http.StatusBadRequest, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ", Errors: []ErrorDetail{
+					{Context: "name", Message: "Name is required.", ExceptionName: "com.atlassian.bitbucket.validation.ArgumentValidationException"},
+					{Message: "Key is invalid."},
+				}},
+				message: "PUT /rest/api/1.0/projects/PRJ: 400 Bad Request: name: Name is required.; Key is invalid.",
+				is:      []error{ErrBadRequest},
+			},
+		},
+		"NotJSON": {
+			reason:      "We should report the status of a response whose body is not JSON, e.g. one of a proxy.",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html><body>Bad Gateway</body></html>",
+			want: want{
+				err:     &APIError{StatusCode: http.StatusBadGateway, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 502 Bad Gateway",
+				is:      []error{ErrServer},
+			},
+		},
+		"EmptyBody": {
+			reason: "We should report the status of a response without a body.",
+			status: http.StatusNotFound,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusNotFound, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 404 Not Found",
+				is:      []error{ErrNotFound},
+			},
+		},
+		"Unauthorized": {
+			reason: "We should match an unauthenticated request with ErrPermission only.",
+			status: http.StatusUnauthorized,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusUnauthorized, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 401 Unauthorized",
+				is:      []error{ErrPermission},
+			},
+		},
+		"Forbidden": {
+			reason: "We should match a forbidden request with both ErrForbidden and ErrPermission.",
+			status: http.StatusForbidden,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusForbidden, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 403 Forbidden",
+				is:      []error{ErrPermission, ErrForbidden},
+			},
+		},
+		"Conflict": {
+			reason: "We should match a conflicting request with ErrConflict.",
+			status: http.StatusConflict,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusConflict, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 409 Conflict",
+				is:      []error{ErrConflict},
+			},
+		},
+		"InternalServerError": {
+			reason: "We should match a failure of the server with ErrServer.",
+			status: http.StatusInternalServerError,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusInternalServerError, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 500 Internal Server Error",
+				is:      []error{ErrServer},
+			},
+		},
+		"TooManyRequests": {
+			reason: "We should not match a status without a sentinel with any sentinel.",
+			status: http.StatusTooManyRequests,
+			want: want{
+				err:     &APIError{StatusCode: http.StatusTooManyRequests, Method: http.MethodPut, Path: "/rest/api/1.0/projects/PRJ"},
+				message: "PUT /rest/api/1.0/projects/PRJ: 429 Too Many Requests",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv)
+			req, err := c.newRequest(coreAPI, http.MethodPut, "projects/PRJ", nil)
+			if err != nil {
+				t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+			}
+			err = c.do(context.Background(), req, nil)
+
+			var got *APIError
+			if !errors.As(err, &got) {
+				t.Fatalf("\n%s\nc.do(...): want an *APIError, got %T: %v", tc.reason, err, err)
+			}
+			if diff := cmp.Diff(tc.want.err, got); diff != "" {
+				t.Errorf("\n%s\nc.do(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, got.Error()); diff != "" {
+				t.Errorf("\n%s\nerr.Error(): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			for _, s := range sentinels {
+				want := false
+				for _, is := range tc.want.is {
+					want = want || is == s
+				}
+				if got := errors.Is(err, s); got != want {
+					t.Errorf("\n%s\nerrors.Is(err, %v): want %t, got %t", tc.reason, s, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package fake contains mock implementations of the bitbucket services for
+// use in controller tests.
+package fake
+
+import (
+	"context"
+
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+var _ bitbucket.ProjectService = &MockProjectService{}
+
+// MockProjectService is a mock implementation of bitbucket.ProjectService
+type MockProjectService struct {
+	MockGetProject    func(context.Context, *bitbucket.GetProjectRequest) (*bitbucket.Project, error)
+	MockCreateProject func(context.Context, *bitbucket.CreateProjectRequest) (*bitbucket.Project, error)
+	MockDeleteProject func(context.Context, *bitbucket.DeleteProjectRequest) error
+	MockUpdateProject func(context.Context, *bitbucket.UpdateProjectRequest) (*bitbucket.Project, error)
+}
+
+// GetProject calls MockGetProject
+func (m *MockProjectService) GetProject(ctx context.Context, req *bitbucket.GetProjectRequest) (*bitbucket.Project, error) {
+	return m.MockGetProject(ctx, req)
+}
+
+// CreateProject calls MockCreateProject
+func (m *MockProjectService) CreateProject(ctx context.Context, req *bitbucket.CreateProjectRequest) (*bitbucket.Project, error) {
+	return m.MockCreateProject(ctx, req)
+}
+
+// DeleteProject calls MockDeleteProject
+func (m *MockProjectService) DeleteProject(ctx context.Context, req *bitbucket.DeleteProjectRequest) error {
+	return m.MockDeleteProject(ctx, req)
+}
+
+// UpdateProject calls MockUpdateProject
+func (m *MockProjectService) UpdateProject(ctx context.Context, req *bitbucket.UpdateProjectRequest) (*bitbucket.Project, error) {
+	return m.MockUpdateProject(ctx, req)
+}
+
+var _ bitbucket.RepositoryService = &MockRepositoryService{}
+
+// MockRepositoryService is a mock implementation of bitbucket.RepositoryService
+type MockRepositoryService struct {
+	MockGetRepository    func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error)
+	MockCreateRepository func(context.Context, *bitbucket.CreateRepositoryRequest) (*bitbucket.Repository, error)
+	MockDeleteRepository func(context.Context, *bitbucket.DeleteRepositoryRequest) error
+	MockUpdateRepository func(context.Context, *bitbucket.UpdateRepositoryRequest) (*bitbucket.Repository, error)
+	MockGetDefaultBranch func(context.Context, *bitbucket.GetDefaultBranchRequest) (*bitbucket.Branch, error)
+	MockSetDefaultBranch func(context.Context, *bitbucket.SetDefaultBranchRequest) error
+}
+
+// GetRepository calls MockGetRepository
+func (m *MockRepositoryService) GetRepository(ctx context.Context, req *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+	return m.MockGetRepository(ctx, req)
+}
+
+// CreateRepository calls MockCreateRepository
+func (m *MockRepositoryService) CreateRepository(ctx context.Context, req *bitbucket.CreateRepositoryRequest) (*bitbucket.Repository, error) {
+	return m.MockCreateRepository(ctx, req)
+}
+
+// DeleteRepository calls MockDeleteRepository
+func (m *MockRepositoryService) DeleteRepository(ctx context.Context, req *bitbucket.DeleteRepositoryRequest) error {
+	return m.MockDeleteRepository(ctx, req)
+}
+
+// UpdateRepository calls MockUpdateRepository
+func (m *MockRepositoryService) UpdateRepository(ctx context.Context, req *bitbucket.UpdateRepositoryRequest) (*bitbucket.Repository, error) {
+	return m.MockUpdateRepository(ctx, req)
+}
+
+// GetDefaultBranch calls MockGetDefaultBranch
+func (m *MockRepositoryService) GetDefaultBranch(ctx context.Context, req *bitbucket.GetDefaultBranchRequest) (*bitbucket.Branch, error) {
+	return m.MockGetDefaultBranch(ctx, req)
+}
+
+// SetDefaultBranch calls MockSetDefaultBranch
+func (m *MockRepositoryService) SetDefaultBranch(ctx context.Context, req *bitbucket.SetDefaultBranchRequest) error {
+	return m.MockSetDefaultBranch(ctx, req)
+}
+
+var _ bitbucket.PermissionService = &MockPermissionService{}
+
+// MockPermissionService is a mock implementation of bitbucket.PermissionService
+type MockPermissionService struct {
+	MockGetProjectUserPermission     func(context.Context, *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error)
+	MockSetProjectUserPermission     func(context.Context, *bitbucket.SetProjectPermissionRequest) error
+	MockRevokeProjectUserPermission  func(context.Context, *bitbucket.RevokeProjectPermissionRequest) error
+	MockGetProjectGroupPermission    func(context.Context, *bitbucket.GetProjectPermissionRequest) (*bitbucket.GroupPermission, error)
+	MockSetProjectGroupPermission    func(context.Context, *bitbucket.SetProjectPermissionRequest) error
+	MockRevokeProjectGroupPermission func(context.Context, *bitbucket.RevokeProjectPermissionRequest) error
+
+	MockGetRepositoryUserPermission     func(context.Context, *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.UserPermission, error)
+	MockSetRepositoryUserPermission     func(context.Context, *bitbucket.SetRepositoryPermissionRequest) error
+	MockRevokeRepositoryUserPermission  func(context.Context, *bitbucket.RevokeRepositoryPermissionRequest) error
+	MockGetRepositoryGroupPermission    func(context.Context, *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.GroupPermission, error)
+	MockSetRepositoryGroupPermission    func(context.Context, *bitbucket.SetRepositoryPermissionRequest) error
+	MockRevokeRepositoryGroupPermission func(context.Context, *bitbucket.RevokeRepositoryPermissionRequest) error
+}
+
+// GetProjectUserPermission calls MockGetProjectUserPermission
+func (m *MockPermissionService) GetProjectUserPermission(ctx context.Context, req *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+	return m.MockGetProjectUserPermission(ctx, req)
+}
+
+// SetProjectUserPermission calls MockSetProjectUserPermission
+func (m *MockPermissionService) SetProjectUserPermission(ctx context.Context, req *bitbucket.SetProjectPermissionRequest) error {
+	return m.MockSetProjectUserPermission(ctx, req)
+}
+
+// RevokeProjectUserPermission calls MockRevokeProjectUserPermission
+func (m *MockPermissionService) RevokeProjectUserPermission(ctx context.Context, req *bitbucket.RevokeProjectPermissionRequest) error {
+	return m.MockRevokeProjectUserPermission(ctx, req)
+}
+
+// GetProjectGroupPermission calls MockGetProjectGroupPermission
+func (m *MockPermissionService) GetProjectGroupPermission(ctx context.Context, req *bitbucket.GetProjectPermissionRequest) (*bitbucket.GroupPermission, error) {
+	return m.MockGetProjectGroupPermission(ctx, req)
+}
+
+// SetProjectGroupPermission calls MockSetProjectGroupPermission
+func (m *MockPermissionService) SetProjectGroupPermission(ctx context.Context, req *bitbucket.SetProjectPermissionRequest) error {
+	return m.MockSetProjectGroupPermission(ctx, req)
+}
+
+// RevokeProjectGroupPermission calls MockRevokeProjectGroupPermission
+func (m *MockPermissionService) RevokeProjectGroupPermission(ctx context.Context, req *bitbucket.RevokeProjectPermissionRequest) error {
+	return m.MockRevokeProjectGroupPermission(ctx, req)
+}
+
+// GetRepositoryUserPermission calls MockGetRepositoryUserPermission
+func (m *MockPermissionService) GetRepositoryUserPermission(ctx context.Context, req *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.UserPermission, error) {
+	return m.MockGetRepositoryUserPermission(ctx, req)
+}
+
+// SetRepositoryUserPermission calls MockSetRepositoryUserPermission
+func (m *MockPermissionService) SetRepositoryUserPermission(ctx context.Context, req *bitbucket.SetRepositoryPermissionRequest) error {
+	return m.MockSetRepositoryUserPermission(ctx, req)
+}
+
+// RevokeRepositoryUserPermission calls MockRevokeRepositoryUserPermission
+func (m *MockPermissionService) RevokeRepositoryUserPermission(ctx context.Context, req *bitbucket.RevokeRepositoryPermissionRequest) error {
+	return m.MockRevokeRepositoryUserPermission(ctx, req)
+}
+
+// GetRepositoryGroupPermission calls MockGetRepositoryGroupPermission
+func (m *MockPermissionService) GetRepositoryGroupPermission(ctx context.Context, req *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.GroupPermission, error) {
+	return m.MockGetRepositoryGroupPermission(ctx, req)
+}
+
+// SetRepositoryGroupPermission calls MockSetRepositoryGroupPermission
+func (m *MockPermissionService) SetRepositoryGroupPermission(ctx context.Context, req *bitbucket.SetRepositoryPermissionRequest) error {
+	return m.MockSetRepositoryGroupPermission(ctx, req)
+}
+
+// RevokeRepositoryGroupPermission calls MockRevokeRepositoryGroupPermission
+func (m *MockPermissionService) RevokeRepositoryGroupPermission(ctx context.Context, req *bitbucket.RevokeRepositoryPermissionRequest) error {
+	return m.MockRevokeRepositoryGroupPermission(ctx, req)
+}
+
+var _ bitbucket.BranchRestrictionService = &MockBranchRestrictionService{}
+
+// MockBranchRestrictionService is a mock implementation of bitbucket.BranchRestrictionService
+type MockBranchRestrictionService struct {
+	MockGetBranchRestriction    func(context.Context, *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error)
+	MockCreateBranchRestriction func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error)
+	MockDeleteBranchRestriction func(context.Context, *bitbucket.DeleteBranchRestrictionRequest) error
+}
+
+// GetBranchRestriction calls MockGetBranchRestriction
+func (m *MockBranchRestrictionService) GetBranchRestriction(ctx context.Context, req *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+	return m.MockGetBranchRestriction(ctx, req)
+}
+
+// CreateBranchRestriction calls MockCreateBranchRestriction
+func (m *MockBranchRestrictionService) CreateBranchRestriction(ctx context.Context, req *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+	return m.MockCreateBranchRestriction(ctx, req)
+}
+
+// DeleteBranchRestriction calls MockDeleteBranchRestriction
+func (m *MockBranchRestrictionService) DeleteBranchRestriction(ctx context.Context, req *bitbucket.DeleteBranchRestrictionRequest) error {
+	return m.MockDeleteBranchRestriction(ctx, req)
+}
+
+var _ bitbucket.WebhookService = &MockWebhookService{}
+
+// MockWebhookService is a mock implementation of bitbucket.WebhookService
+type MockWebhookService struct {
+	MockGetWebhook    func(context.Context, *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error)
+	MockCreateWebhook func(context.Context, *bitbucket.CreateWebhookRequest) (*bitbucket.Webhook, error)
+	MockUpdateWebhook func(context.Context, *bitbucket.UpdateWebhookRequest) (*bitbucket.Webhook, error)
+	MockDeleteWebhook func(context.Context, *bitbucket.DeleteWebhookRequest) error
+}
+
+// GetWebhook calls MockGetWebhook
+func (m *MockWebhookService) GetWebhook(ctx context.Context, req *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+	return m.MockGetWebhook(ctx, req)
+}
+
+// CreateWebhook calls MockCreateWebhook
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, req *bitbucket.CreateWebhookRequest) (*bitbucket.Webhook, error) {
+	return m.MockCreateWebhook(ctx, req)
+}
+
+// UpdateWebhook calls MockUpdateWebhook
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, req *bitbucket.UpdateWebhookRequest) (*bitbucket.Webhook, error) {
+	return m.MockUpdateWebhook(ctx, req)
+}
+
+// DeleteWebhook calls MockDeleteWebhook
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, req *bitbucket.DeleteWebhookRequest) error {
+	return m.MockDeleteWebhook(ctx, req)
+}
+
+var _ bitbucket.DefaultReviewerService = &MockDefaultReviewerService{}
+
+// MockDefaultReviewerService is a mock implementation of bitbucket.DefaultReviewerService
+type MockDefaultReviewerService struct {
+	MockGetCondition    func(context.Context, *bitbucket.GetConditionRequest) (*bitbucket.Condition, error)
+	MockCreateCondition func(context.Context, *bitbucket.CreateConditionRequest) (*bitbucket.Condition, error)
+	MockUpdateCondition func(context.Context, *bitbucket.UpdateConditionRequest) (*bitbucket.Condition, error)
+	MockDeleteCondition func(context.Context, *bitbucket.DeleteConditionRequest) error
+}
+
+// GetCondition calls MockGetCondition
+func (m *MockDefaultReviewerService) GetCondition(ctx context.Context, req *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+	return m.MockGetCondition(ctx, req)
+}
+
+// CreateCondition calls MockCreateCondition
+func (m *MockDefaultReviewerService) CreateCondition(ctx context.Context, req *bitbucket.CreateConditionRequest) (*bitbucket.Condition, error) {
+	return m.MockCreateCondition(ctx, req)
+}
+
+// UpdateCondition calls MockUpdateCondition
+func (m *MockDefaultReviewerService) UpdateCondition(ctx context.Context, req *bitbucket.UpdateConditionRequest) (*bitbucket.Condition, error) {
+	return m.MockUpdateCondition(ctx, req)
+}
+
+// DeleteCondition calls MockDeleteCondition
+func (m *MockDefaultReviewerService) DeleteCondition(ctx context.Context, req *bitbucket.DeleteConditionRequest) error {
+	return m.MockDeleteCondition(ctx, req)
+}
+
+var _ bitbucket.UserService = &MockUserService{}
+
+// MockUserService is a mock implementation of bitbucket.UserService
+type MockUserService struct {
+	MockFindUser func(context.Context, *bitbucket.FindUserRequest) (*bitbucket.User, error)
+}
+
+// FindUser calls MockFindUser
+func (m *MockUserService) FindUser(ctx context.Context, req *bitbucket.FindUserRequest) (*bitbucket.User, error) {
+	return m.MockFindUser(ctx, req)
+}
+
+var _ bitbucket.AccessKeyService = &MockAccessKeyService{}
+
+// MockAccessKeyService is a mock implementation of bitbucket.AccessKeyService
+type MockAccessKeyService struct {
+	MockGetAccessKey              func(context.Context, *bitbucket.GetAccessKeyRequest) (*bitbucket.AccessKey, error)
+	MockCreateAccessKey           func(context.Context, *bitbucket.CreateAccessKeyRequest) (*bitbucket.AccessKey, error)
+	MockUpdateAccessKeyPermission func(context.Context, *bitbucket.UpdateAccessKeyPermissionRequest) (*bitbucket.AccessKey, error)
+	MockDeleteAccessKey           func(context.Context, *bitbucket.DeleteAccessKeyRequest) error
+}
+
+// GetAccessKey calls MockGetAccessKey
+func (m *MockAccessKeyService) GetAccessKey(ctx context.Context, req *bitbucket.GetAccessKeyRequest) (*bitbucket.AccessKey, error) {
+	return m.MockGetAccessKey(ctx, req)
+}
+
+// CreateAccessKey calls MockCreateAccessKey
+func (m *MockAccessKeyService) CreateAccessKey(ctx context.Context, req *bitbucket.CreateAccessKeyRequest) (*bitbucket.AccessKey, error) {
+	return m.MockCreateAccessKey(ctx, req)
+}
+
+// UpdateAccessKeyPermission calls MockUpdateAccessKeyPermission
+func (m *MockAccessKeyService) UpdateAccessKeyPermission(ctx context.Context, req *bitbucket.UpdateAccessKeyPermissionRequest) (*bitbucket.AccessKey, error) {
+	return m.MockUpdateAccessKeyPermission(ctx, req)
+}
+
+// DeleteAccessKey calls MockDeleteAccessKey
+func (m *MockAccessKeyService) DeleteAccessKey(ctx context.Context, req *bitbucket.DeleteAccessKeyRequest) error {
+	return m.MockDeleteAccessKey(ctx, req)
+}
+
+var _ bitbucket.AccessTokenService = &MockAccessTokenService{}
+
+// MockAccessTokenService is a mock implementation of bitbucket.AccessTokenService
+type MockAccessTokenService struct {
+	MockGetAccessToken    func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error)
+	MockCreateAccessToken func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error)
+	MockUpdateAccessToken func(context.Context, *bitbucket.UpdateAccessTokenRequest) (*bitbucket.AccessToken, error)
+	MockDeleteAccessToken func(context.Context, *bitbucket.DeleteAccessTokenRequest) error
+}
+
+// GetAccessToken calls MockGetAccessToken
+func (m *MockAccessTokenService) GetAccessToken(ctx context.Context, req *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+	return m.MockGetAccessToken(ctx, req)
+}
+
+// CreateAccessToken calls MockCreateAccessToken
+func (m *MockAccessTokenService) CreateAccessToken(ctx context.Context, req *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+	return m.MockCreateAccessToken(ctx, req)
+}
+
+// UpdateAccessToken calls MockUpdateAccessToken
+func (m *MockAccessTokenService) UpdateAccessToken(ctx context.Context, req *bitbucket.UpdateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+	return m.MockUpdateAccessToken(ctx, req)
+}
+
+// DeleteAccessToken calls MockDeleteAccessToken
+func (m *MockAccessTokenService) DeleteAccessToken(ctx context.Context, req *bitbucket.DeleteAccessTokenRequest) error {
+	return m.MockDeleteAccessToken(ctx, req)
+}
+
+var _ bitbucket.ApplicationService = &MockApplicationService{}
+
+// MockApplicationService is a mock implementation of bitbucket.ApplicationService
+type MockApplicationService struct {
+	MockGetApplicationProperties func(context.Context) (*bitbucket.ApplicationProperties, error)
+}
+
+// GetApplicationProperties calls MockGetApplicationProperties
+func (m *MockApplicationService) GetApplicationProperties(ctx context.Context) (*bitbucket.ApplicationProperties, error) {
+	return m.MockGetApplicationProperties(ctx)
+}
@@ -0,0 +1,189 @@
+package bitbucket
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// defaultTimeout bounds every request unless configured otherwise
+const defaultTimeout = 10 * time.Second
+
+// Authorization schemes supported by WithAuth
+const (
+	AuthBasic  = "Basic"
+	AuthBearer = "Bearer"
+)
+
+// errCustomTransport is returned when options tuning the transport are given
+// along with an HTTP client whose transport cannot be tuned
+var errCustomTransport = errors.New("transport options require the HTTP client to use an *http.Transport")
+
+// Option configures a Client
+type Option func(*settings)
+
+// settings collects what the options configure before the client is built
+type settings struct {
+	httpClient *http.Client
+	timeout    *time.Duration
+	// transport tunes a copy of the transport of the HTTP client
+	transport []func(*http.Transport)
+
+	authorization string
+	userAgent     string
+	headers       map[string]string
+
+	pageSize int
+	retry    RetryPolicy
+	skipPing bool
+
+	// rateLimit and burst configure the rate limiter shared by all clients
+	// of a server, rateLimit being zero when requests are not limited
+	rateLimit float64
+	burst     int
+}
+
+// WithHTTPClient makes the client send requests through hc. The client works
+// on a copy of hc, so other options never modify it.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(s *settings) {
+		s.httpClient = hc
+	}
+}
+
+// WithTimeout bounds every request to d, zero meaning no timeout
+func WithTimeout(d time.Duration) Option {
+	return func(s *settings) {
+		s.timeout = &d
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the server
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(s *settings) {
+		s.transport = append(s.transport, func(t *http.Transport) {
+			t.TLSClientConfig = cfg
+		})
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL, except for requests
+// to the hosts matched by noProxy, which uses the format of the NO_PROXY
+// environment variable
+func WithProxy(proxyURL *url.URL, noProxy []string) Option {
+	return func(s *settings) {
+		cfg := &httpproxy.Config{
+			HTTPProxy:  proxyURL.String(),
+			HTTPSProxy: proxyURL.String(),
+			NoProxy:    strings.Join(noProxy, ","),
+		}
+		proxy := cfg.ProxyFunc()
+		s.transport = append(s.transport, func(t *http.Transport) {
+			t.Proxy = func(req *http.Request) (*url.URL, error) {
+				return proxy(req.URL)
+			}
+		})
+	}
+}
+
+// WithAuth authenticates every request with the given scheme, e.g. AuthBasic
+// with the base64 encoding of username:password, or AuthBearer with an HTTP
+// access token
+func WithAuth(scheme string, credentials string) Option {
+	return func(s *settings) {
+		s.authorization = scheme + " " + credentials
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(ua string) Option {
+	return func(s *settings) {
+		s.userAgent = ua
+	}
+}
+
+// WithHeaders sends the given headers with every request, except for the
+// Authorization header, which is reserved for the credentials of the client
+func WithHeaders(headers map[string]string) Option {
+	return func(s *settings) {
+		for k, v := range headers {
+			if http.CanonicalHeaderKey(k) == "Authorization" {
+				continue
+			}
+			s.headers[k] = v
+		}
+	}
+}
+
+// WithPageSize sets the number of values requested per page of a paged api
+func WithPageSize(n int) Option {
+	return func(s *settings) {
+		if n > 0 {
+			s.pageSize = n
+		}
+	}
+}
+
+// WithRetryPolicy sets how requests that failed transiently are retried
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(s *settings) {
+		s.retry = p
+	}
+}
+
+// WithRateLimit limits the requests to the server to rps per second, allowing
+// bursts of up to burst requests. The limit is shared by every client of the
+// same server, so it bounds the requests of all controllers together. When
+// clients of a server configure different limits, the one configured last
+// applies to all of them.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(s *settings) {
+		s.rateLimit = rps
+		s.burst = burst
+	}
+}
+
+// WithoutPing skips checking that the server can be reached when the client
+// is created
+func WithoutPing() Option {
+	return func(s *settings) {
+		s.skipPing = true
+	}
+}
+
+// buildHTTPClient builds the HTTP client the settings describe
+func (s *settings) buildHTTPClient() (*http.Client, error) {
+	hc := &http.Client{Timeout: defaultTimeout}
+	if s.httpClient != nil {
+		c := *s.httpClient
+		hc = &c
+	}
+	if s.timeout != nil {
+		hc.Timeout = *s.timeout
+	}
+
+	if len(s.transport) == 0 {
+		return hc, nil
+	}
+
+	base := http.DefaultTransport
+	if hc.Transport != nil {
+		base = hc.Transport
+	}
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errCustomTransport
+	}
+	t = t.Clone()
+	for _, f := range s.transport {
+		f(t)
+	}
+	hc.Transport = t
+
+	return hc, nil
+}
@@ -0,0 +1,191 @@
+package bitbucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOptions(t *testing.T) {
+	type want struct {
+		headers  map[string]string
+		pageSize int
+		timeout  time.Duration
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"Defaults": {
+			reason: "We should use the default page size and timeout.",
+			want:   want{headers: map[string]string{}, pageSize: defaultPageSize, timeout: defaultTimeout},
+		},
+		"AuthorizationNotOverridden": {
+			reason: "Custom headers should not override the credentials of the client, whatever their order.",
+			opts: []Option{
+				WithAuth(AuthBearer, "token"),
+				WithHeaders(map[string]string{"authorization": "Basic other", "X-Team": "a"}),
+			},
+			want: want{
+				headers:  map[string]string{"Authorization": "Bearer token", "X-Team": "a"},
+				pageSize: defaultPageSize,
+				timeout:  defaultTimeout,
+			},
+		},
+		"UserAgentOverHeaders": {
+			reason: "The user agent option should take precedence over a User-Agent custom header.",
+			opts: []Option{
+				WithUserAgent("provider-bitbucketserver"),
+				WithHeaders(map[string]string{"User-Agent": "other"}),
+			},
+			want: want{
+				headers:  map[string]string{"User-Agent": "provider-bitbucketserver"},
+				pageSize: defaultPageSize,
+				timeout:  defaultTimeout,
+			},
+		},
+		"LastWins": {
+			reason: "The last of repeated options should win.",
+			opts: []Option{
+				WithAuth(AuthBasic, "creds"),
+				WithAuth(AuthBearer, "token"),
+				WithPageSize(25),
+				WithPageSize(50),
+			},
+			want: want{
+				headers:  map[string]string{"Authorization": "Bearer token"},
+				pageSize: 50,
+				timeout:  defaultTimeout,
+			},
+		},
+		"InvalidPageSize": {
+			reason: "We should ignore page sizes that are not positive.",
+			opts:   []Option{WithPageSize(0)},
+			want:   want{headers: map[string]string{}, pageSize: defaultPageSize, timeout: defaultTimeout},
+		},
+		"TimeoutOverHTTPClient": {
+			reason: "The timeout option should take precedence over the timeout of the HTTP client, whatever their order.",
+			opts: []Option{
+				WithTimeout(time.Minute),
+				WithHTTPClient(&http.Client{Timeout: time.Second}),
+			},
+			want: want{headers: map[string]string{}, pageSize: defaultPageSize, timeout: time.Minute},
+		},
+		"HTTPClientTimeout": {
+			reason: "We should keep the timeout of the HTTP client when no timeout is given.",
+			opts:   []Option{WithHTTPClient(&http.Client{Timeout: time.Second})},
+			want:   want{headers: map[string]string{}, pageSize: defaultPageSize, timeout: time.Second},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(context.Background(), "https://bitbucket.example.com", append(tc.opts, WithoutPing())...)
+			if err != nil {
+				t.Fatalf("\n%s\nNew(...): unexpected error: %s", tc.reason, err)
+			}
+			got := want{headers: c.headers, pageSize: c.pageSize, timeout: c.client.Timeout}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nNew(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHTTPClientNotModified(t *testing.T) {
+	hc := &http.Client{Timeout: time.Second}
+	_, err := New(context.Background(), "https://bitbucket.example.com", WithHTTPClient(hc), WithTimeout(time.Minute), WithoutPing())
+	if err != nil {
+		t.Fatalf("New(...): unexpected error: %s", err)
+	}
+	if hc.Timeout != time.Second {
+		t.Errorf("We should not modify the given HTTP client, its timeout is now %s", hc.Timeout)
+	}
+}
+
+func TestPing(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		status   int
+		opts     []Option
+		requests int32
+		wantErr  bool
+	}{
+		"Reachable": {
+			reason:   "We should check that the server can be reached when the client is created.",
+			status:   http.StatusOK,
+			requests: 1,
+		},
+		"Unauthorized": {
+			reason:   "We should return an error when the server rejects the credentials.",
+			status:   http.StatusUnauthorized,
+			requests: 1,
+			wantErr:  true,
+		},
+		"WithoutPing": {
+			reason:   "We should not contact the server when told not to ping it.",
+			status:   http.StatusUnauthorized,
+			opts:     []Option{WithoutPing()},
+			requests: 0,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				writeJSON(w, tc.status, Page[Project]{IsLastPage: true})
+			}))
+			defer srv.Close()
+
+			_, err := New(context.Background(), srv.URL, tc.opts...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nNew(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.requests, atomic.LoadInt32(&requests)); diff != "" {
+				t.Errorf("\n%s\nNew(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		writeJSON(w, http.StatusOK, Page[Project]{IsLastPage: true})
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL+"/bitbucket", "Y3JlZHM=", WithUserAgent("test"))
+	if err != nil {
+		t.Fatalf("NewClient(...): unexpected error: %s", err)
+	}
+
+	// NewClient is New with basic auth, so it pings the server with the
+	// given credentials and passes the other options on.
+	if got == nil {
+		t.Fatal("NewClient(...): want the server to be pinged")
+	}
+	if diff := cmp.Diff("/bitbucket/rest/api/1.0/projects", got.URL.Path); diff != "" {
+		t.Errorf("NewClient(...): -want ping path, +got ping path:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(url.Values{"start": {"0"}, "limit": {"1"}}, got.URL.Query()); diff != "" {
+		t.Errorf("NewClient(...): -want ping query, +got ping query:\n%s\n", diff)
+	}
+	if diff := cmp.Diff("Basic Y3JlZHM=", got.Header.Get("Authorization")); diff != "" {
+		t.Errorf("NewClient(...): -want authorization, +got authorization:\n%s\n", diff)
+	}
+	if diff := cmp.Diff("test", c.headers["User-Agent"]); diff != "" {
+		t.Errorf("NewClient(...): -want user agent, +got user agent:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,132 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// defaultPageSize is the number of values requested per page unless
+// configured otherwise
+const defaultPageSize = 100
+
+// Page is a single page of a paged bitbucket response
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/intro/#paged-apis
+type Page[T any] struct {
+	Values        []T  `json:"values"`
+	Size          int  `json:"size"`
+	Limit         int  `json:"limit"`
+	Start         int  `json:"start"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// PageIterator walks through every value of a paged collection, fetching the
+// pages as they are needed
+type PageIterator[T any] struct {
+	service  *service
+	path     string
+	pageSize int
+
+	page *Page[T]
+	i    int
+	err  error
+}
+
+// newPageIterator creates an iterator over the collection found at path, which
+// may already carry a query of its own, e.g. a filter
+func newPageIterator[T any](s *service, path string) *PageIterator[T] {
+	return &PageIterator[T]{service: s, path: path, pageSize: s.client.pageSize}
+}
+
+// WithPageSize sets the number of values requested per page
+func (it *PageIterator[T]) WithPageSize(n int) *PageIterator[T] {
+	it.pageSize = n
+	return it
+}
+
+// Next advances the iterator to the next value, fetching the next page when
+// the current one is exhausted. It returns false once every value has been
+// visited or fetching a page failed, which Err then reports.
+func (it *PageIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.page != nil && it.i+1 < len(it.page.Values) {
+		it.i++
+		return true
+	}
+
+	// pages may be empty, so keep fetching until one has values
+	for it.page == nil || !it.page.IsLastPage {
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		start := 0
+		if it.page != nil {
+			start = it.page.NextPageStart
+		}
+
+		page, err := it.fetch(ctx, start)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		// guard against looping forever on a server that does not advance
+		if !page.IsLastPage && page.NextPageStart <= start {
+			it.err = ErrResponseMalformed
+			return false
+		}
+
+		it.page = page
+		it.i = 0
+		if len(page.Values) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Value returns the value the iterator is at. It must only be called after
+// Next returned true.
+func (it *PageIterator[T]) Value() T {
+	return it.page.Values[it.i]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *PageIterator[T]) Err() error {
+	return it.err
+}
+
+// All collects every remaining value of the collection
+func (it *PageIterator[T]) All(ctx context.Context) ([]T, error) {
+	values := []T{}
+	for it.Next(ctx) {
+		values = append(values, it.Value())
+	}
+	return values, it.Err()
+}
+
+func (it *PageIterator[T]) fetch(ctx context.Context, start int) (*Page[T], error) {
+	req, err := it.service.newRequest("GET", it.path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting page: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Set("start", strconv.Itoa(start))
+	q.Set("limit", strconv.Itoa(it.pageSize))
+	req.URL.RawQuery = q.Encode()
+
+	page := &Page[T]{}
+	err = it.service.client.do(ctx, req, page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
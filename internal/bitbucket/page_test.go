@@ -0,0 +1,188 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// pagedServer serves the given pages of a collection, keyed by their start,
+// and records the starts and limits it was asked for
+type pagedServer[T any] struct {
+	pages  map[int]Page[T]
+	starts []int
+	limits []string
+	query  []string
+}
+
+func (s *pagedServer[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	s.starts = append(s.starts, start)
+	s.limits = append(s.limits, r.URL.Query().Get("limit"))
+	s.query = append(s.query, r.URL.Query().Get("filter"))
+
+	p, ok := s.pages[start]
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func TestPageIterator(t *testing.T) {
+	type want struct {
+		values []int
+		starts []int
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		pages  map[int]Page[int]
+		want   want
+	}{
+		"Empty": {
+			reason: "We should visit no values of an empty collection.",
+			pages:  map[int]Page[int]{0: {IsLastPage: true}},
+			want:   want{values: []int{}, starts: []int{0}},
+		},
+		"SinglePage": {
+			reason: "We should visit every value of a single page.",
+			pages:  map[int]Page[int]{0: {Values: []int{1, 2}, IsLastPage: true}},
+			want:   want{values: []int{1, 2}, starts: []int{0}},
+		},
+		"MultiplePages": {
+			reason: "We should fetch the next page from where the previous one ended until the last page.",
+			pages: map[int]Page[int]{
+				0: {Values: []int{1, 2}, NextPageStart: 2},
+				2: {Values: []int{3, 4}, NextPageStart: 4},
+				4: {Values: []int{5}, IsLastPage: true},
+			},
+			want: want{values: []int{1, 2, 3, 4, 5}, starts: []int{0, 2, 4}},
+		},
+		"EmptyPages": {
+			reason: "We should skip empty pages, including an empty last page.",
+			pages: map[int]Page[int]{
+				0:  {NextPageStart: 25},
+				25: {Values: []int{1}, NextPageStart: 50},
+				50: {IsLastPage: true},
+			},
+			want: want{values: []int{1}, starts: []int{0, 25, 50}},
+		},
+		"NotAdvancing": {
+			reason: "We should stop with an error rather than loop forever on a page that does not advance.",
+			pages: map[int]Page[int]{
+				0: {Values: []int{1}, NextPageStart: 1},
+				1: {Values: []int{2}, NextPageStart: 1},
+			},
+			want: want{values: []int{1}, starts: []int{0, 1}, err: ErrResponseMalformed},
+		},
+		"FetchFailed": {
+			reason: "We should stop with the error of a page that can not be fetched.",
+			pages: map[int]Page[int]{
+				0: {Values: []int{1}, NextPageStart: 1},
+			},
+			want: want{values: []int{1}, starts: []int{0, 1}, err: ErrServer},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ps := &pagedServer[int]{pages: tc.pages}
+			srv := httptest.NewServer(ps)
+			defer srv.Close()
+
+			c := newTestClient(t, srv, WithPageSize(2))
+			got, err := newPageIterator[int](&service{client: c, api: coreAPI}, "values").All(context.Background())
+			if !errors.Is(err, tc.want.err) {
+				t.Errorf("\n%s\nAll(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.values, got); diff != "" {
+				t.Errorf("\n%s\nAll(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.starts, ps.starts); diff != "" {
+				t.Errorf("\n%s\nAll(...): -want starts, +got starts:\n%s\n", tc.reason, diff)
+			}
+			for _, l := range ps.limits {
+				if l != "2" {
+					t.Errorf("\n%s\nAll(...): want pages of 2 values, got limit %s", tc.reason, l)
+				}
+			}
+		})
+	}
+}
+
+func TestPageIteratorCancelled(t *testing.T) {
+	ps := &pagedServer[int]{pages: map[int]Page[int]{0: {IsLastPage: true}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := newTestClient(t, srv)
+	_, err := newPageIterator[int](&service{client: c, api: coreAPI}, "values").All(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("All(...): want %v, got %v", context.Canceled, err)
+	}
+	if len(ps.starts) != 0 {
+		t.Errorf("All(...): want no pages fetched once cancelled, got %v", ps.starts)
+	}
+}
+
+func TestGetProjectUserPermissionPaged(t *testing.T) {
+	permission := func(name, perm string) UserPermission {
+		return UserPermission{User: User{Name: name}, Permission: perm}
+	}
+
+	cases := map[string]struct {
+		reason string
+		pages  map[int]Page[UserPermission]
+		want   *UserPermission
+		err    error
+	}{
+		"LaterPage": {
+			reason: "We should look for the user past the first page of the substrings matching the filter.",
+			pages: map[int]Page[UserPermission]{
+				0: {Values: []UserPermission{permission("jdoe2", "PROJECT_READ"), permission("ajdoe", "PROJECT_READ")}, NextPageStart: 2},
+				2: {Values: []UserPermission{permission("JDoe", "PROJECT_WRITE")}, IsLastPage: true},
+			},
+			want: &UserPermission{User: User{Name: "JDoe"}, Permission: "PROJECT_WRITE"},
+		},
+		"NotGranted": {
+			reason: "We should report a user missing from every page as not found.",
+			pages: map[int]Page[UserPermission]{
+				0: {Values: []UserPermission{permission("jdoe2", "PROJECT_READ")}, NextPageStart: 1},
+				1: {IsLastPage: true},
+			},
+			err: ErrNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ps := &pagedServer[UserPermission]{pages: tc.pages}
+			srv := httptest.NewServer(ps)
+			defer srv.Close()
+
+			c := newTestClient(t, srv, WithPageSize(2))
+			got, err := c.Permissions.GetProjectUserPermission(context.Background(), &GetProjectPermissionRequest{ProjectKey: "PRJ", Name: "jdoe"})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("\n%s\nGetProjectUserPermission(...): want error %v, got %v", tc.reason, tc.err, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetProjectUserPermission(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			for _, q := range ps.query {
+				if q != "jdoe" {
+					t.Errorf("\n%s\nGetProjectUserPermission(...): want every page filtered by jdoe, got %q", tc.reason, q)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PermissionService provides operations around the permissions users and
+// groups are granted on bitbucket projects and repositories
+type PermissionService interface {
+	GetProjectUserPermission(context.Context, *GetProjectPermissionRequest) (*UserPermission, error)
+	SetProjectUserPermission(context.Context, *SetProjectPermissionRequest) error
+	RevokeProjectUserPermission(context.Context, *RevokeProjectPermissionRequest) error
+
+	GetProjectGroupPermission(context.Context, *GetProjectPermissionRequest) (*GroupPermission, error)
+	SetProjectGroupPermission(context.Context, *SetProjectPermissionRequest) error
+	RevokeProjectGroupPermission(context.Context, *RevokeProjectPermissionRequest) error
+
+	GetRepositoryUserPermission(context.Context, *GetRepositoryPermissionRequest) (*UserPermission, error)
+	SetRepositoryUserPermission(context.Context, *SetRepositoryPermissionRequest) error
+	RevokeRepositoryUserPermission(context.Context, *RevokeRepositoryPermissionRequest) error
+
+	GetRepositoryGroupPermission(context.Context, *GetRepositoryPermissionRequest) (*GroupPermission, error)
+	SetRepositoryGroupPermission(context.Context, *SetRepositoryPermissionRequest) error
+	RevokeRepositoryGroupPermission(context.Context, *RevokeRepositoryPermissionRequest) error
+}
+
+type permissionService struct {
+	service
+}
+
+// User represents a Bitbucket User
+type User struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	ID           int    `json:"id"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Active       bool   `json:"active"`
+	Slug         string `json:"slug"`
+	Type         string `json:"type,omitempty"`
+}
+
+// Group represents a Bitbucket Group
+type Group struct {
+	Name string `json:"name"`
+}
+
+// UserPermission is a permission granted to a single user
+type UserPermission struct {
+	User       User   `json:"user"`
+	Permission string `json:"permission"`
+}
+
+// GroupPermission is a permission granted to a group
+type GroupPermission struct {
+	Group      Group  `json:"group"`
+	Permission string `json:"permission"`
+}
+
+// GetProjectPermissionRequest contains the fields required to fetch the
+// permission of a user or group on a project
+type GetProjectPermissionRequest struct {
+	ProjectKey string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+}
+
+func (ps *permissionService) GetProjectUserPermission(ctx context.Context, getReq *GetProjectPermissionRequest) (*UserPermission, error) {
+	p, err := ps.getUserPermission(ctx, projectPermissionsPath(getReq.ProjectKey, "users", url.Values{"filter": {getReq.Name}}), getReq.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching project user permission: %w", err)
+	}
+	return p, nil
+}
+
+func (ps *permissionService) GetProjectGroupPermission(ctx context.Context, getReq *GetProjectPermissionRequest) (*GroupPermission, error) {
+	p, err := ps.getGroupPermission(ctx, projectPermissionsPath(getReq.ProjectKey, "groups", url.Values{"filter": {getReq.Name}}), getReq.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching project group permission: %w", err)
+	}
+	return p, nil
+}
+
+// SetProjectPermissionRequest contains the fields required to grant a user or
+// group a permission on a project
+type SetProjectPermissionRequest struct {
+	ProjectKey string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+	// Permission is one of PROJECT_READ, PROJECT_WRITE or PROJECT_ADMIN
+	Permission string `json:"-"`
+}
+
+func (ps *permissionService) SetProjectUserPermission(ctx context.Context, setReq *SetProjectPermissionRequest) error {
+	req, err := ps.newRequest("PUT", projectPermissionsPath(setReq.ProjectKey, "users", url.Values{"name": {setReq.Name}, "permission": {setReq.Permission}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for setting project user permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error setting project user permission: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *permissionService) SetProjectGroupPermission(ctx context.Context, setReq *SetProjectPermissionRequest) error {
+	req, err := ps.newRequest("PUT", projectPermissionsPath(setReq.ProjectKey, "groups", url.Values{"name": {setReq.Name}, "permission": {setReq.Permission}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for setting project group permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error setting project group permission: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeProjectPermissionRequest contains the fields required to revoke all
+// permissions of a user or group on a project
+type RevokeProjectPermissionRequest struct {
+	ProjectKey string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+}
+
+func (ps *permissionService) RevokeProjectUserPermission(ctx context.Context, revokeReq *RevokeProjectPermissionRequest) error {
+	req, err := ps.newRequest("DELETE", projectPermissionsPath(revokeReq.ProjectKey, "users", url.Values{"name": {revokeReq.Name}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for revoking project user permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error revoking project user permission: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *permissionService) RevokeProjectGroupPermission(ctx context.Context, revokeReq *RevokeProjectPermissionRequest) error {
+	req, err := ps.newRequest("DELETE", projectPermissionsPath(revokeReq.ProjectKey, "groups", url.Values{"name": {revokeReq.Name}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for revoking project group permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error revoking project group permission: %w", err)
+	}
+
+	return nil
+}
+
+// GetRepositoryPermissionRequest contains the fields required to fetch the
+// permission of a user or group on a repository
+type GetRepositoryPermissionRequest struct {
+	ProjectKey     string `json:"-"`
+	RepositorySlug string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+}
+
+func (ps *permissionService) GetRepositoryUserPermission(ctx context.Context, getReq *GetRepositoryPermissionRequest) (*UserPermission, error) {
+	p, err := ps.getUserPermission(ctx, repositoryPermissionsPath(getReq.ProjectKey, getReq.RepositorySlug, "users", url.Values{"filter": {getReq.Name}}), getReq.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching repository user permission: %w", err)
+	}
+	return p, nil
+}
+
+func (ps *permissionService) GetRepositoryGroupPermission(ctx context.Context, getReq *GetRepositoryPermissionRequest) (*GroupPermission, error) {
+	p, err := ps.getGroupPermission(ctx, repositoryPermissionsPath(getReq.ProjectKey, getReq.RepositorySlug, "groups", url.Values{"filter": {getReq.Name}}), getReq.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching repository group permission: %w", err)
+	}
+	return p, nil
+}
+
+// SetRepositoryPermissionRequest contains the fields required to grant a user
+// or group a permission on a repository
+type SetRepositoryPermissionRequest struct {
+	ProjectKey     string `json:"-"`
+	RepositorySlug string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+	// Permission is one of REPO_READ, REPO_WRITE or REPO_ADMIN
+	Permission string `json:"-"`
+}
+
+func (ps *permissionService) SetRepositoryUserPermission(ctx context.Context, setReq *SetRepositoryPermissionRequest) error {
+	req, err := ps.newRequest("PUT", repositoryPermissionsPath(setReq.ProjectKey, setReq.RepositorySlug, "users", url.Values{"name": {setReq.Name}, "permission": {setReq.Permission}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for setting repository user permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error setting repository user permission: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *permissionService) SetRepositoryGroupPermission(ctx context.Context, setReq *SetRepositoryPermissionRequest) error {
+	req, err := ps.newRequest("PUT", repositoryPermissionsPath(setReq.ProjectKey, setReq.RepositorySlug, "groups", url.Values{"name": {setReq.Name}, "permission": {setReq.Permission}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for setting repository group permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error setting repository group permission: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRepositoryPermissionRequest contains the fields required to revoke all
+// permissions of a user or group on a repository
+type RevokeRepositoryPermissionRequest struct {
+	ProjectKey     string `json:"-"`
+	RepositorySlug string `json:"-"`
+	// Name of the user or group
+	Name string `json:"-"`
+}
+
+func (ps *permissionService) RevokeRepositoryUserPermission(ctx context.Context, revokeReq *RevokeRepositoryPermissionRequest) error {
+	req, err := ps.newRequest("DELETE", repositoryPermissionsPath(revokeReq.ProjectKey, revokeReq.RepositorySlug, "users", url.Values{"name": {revokeReq.Name}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for revoking repository user permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error revoking repository user permission: %w", err)
+	}
+
+	return nil
+}
+
+func (ps *permissionService) RevokeRepositoryGroupPermission(ctx context.Context, revokeReq *RevokeRepositoryPermissionRequest) error {
+	req, err := ps.newRequest("DELETE", repositoryPermissionsPath(revokeReq.ProjectKey, revokeReq.RepositorySlug, "groups", url.Values{"name": {revokeReq.Name}}), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for revoking repository group permission: %w", err)
+	}
+
+	err = ps.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error revoking repository group permission: %w", err)
+	}
+
+	return nil
+}
+
+// getUserPermission looks up the permission of a single user in the filtered
+// permissions found at path. The filter matches substrings, so the user has to
+// be looked for in the results.
+func (ps *permissionService) getUserPermission(ctx context.Context, path string, name string) (*UserPermission, error) {
+	it := newPageIterator[UserPermission](&ps.service, path)
+	for it.Next(ctx) {
+		p := it.Value()
+		if strings.EqualFold(p.User.Name, name) {
+			return &p, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotFound
+}
+
+// getGroupPermission looks up the permission of a single group in the filtered
+// permissions found at path. The filter matches substrings, so the group has
+// to be looked for in the results.
+func (ps *permissionService) getGroupPermission(ctx context.Context, path string, name string) (*GroupPermission, error) {
+	it := newPageIterator[GroupPermission](&ps.service, path)
+	for it.Next(ctx) {
+		p := it.Value()
+		if strings.EqualFold(p.Group.Name, name) {
+			return &p, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrNotFound
+}
+
+// projectPermissionsPath builds the path of the user or group permissions of a project
+func projectPermissionsPath(projectKey string, grantee string, query url.Values) string {
+	return fmt.Sprintf("projects/%s/permissions/%s?%s", url.PathEscape(projectKey), grantee, query.Encode())
+}
+
+// repositoryPermissionsPath builds the path of the user or group permissions of a repository
+func repositoryPermissionsPath(projectKey string, slug string, grantee string, query url.Values) string {
+	return fmt.Sprintf("projects/%s/repos/%s/permissions/%s?%s", url.PathEscape(projectKey), url.PathEscape(slug), grantee, query.Encode())
+}
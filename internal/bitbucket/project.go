@@ -3,6 +3,7 @@ package bitbucket
 import (
 	"context"
 	"fmt"
+	"net/url"
 )
 
 // ProjectService provides operations around bitbucket projects
@@ -14,7 +15,7 @@ type ProjectService interface {
 }
 
 type projectService struct {
-	client *Client
+	service
 }
 
 // Project represents a Bitbucket Project
@@ -34,7 +35,7 @@ type GetProjectRequest struct {
 }
 
 func (ps *projectService) GetProject(ctx context.Context, getReq *GetProjectRequest) (*Project, error) {
-	req, err := ps.client.newRequest("GET", fmt.Sprintf("projects/%s", getReq.Key), nil)
+	req, err := ps.newRequest("GET", fmt.Sprintf("projects/%s", url.PathEscape(getReq.Key)), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request for getting projects: %w", err)
 	}
@@ -56,7 +57,7 @@ type CreateProjectRequest struct {
 }
 
 func (ps *projectService) CreateProject(ctx context.Context, createReq *CreateProjectRequest) (*Project, error) {
-	req, err := ps.client.newRequest("POST", "projects", createReq)
+	req, err := ps.newRequest("POST", "projects", createReq)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request for creating project: %w", err)
 	}
@@ -76,7 +77,7 @@ type DeleteProjectRequest struct {
 }
 
 func (ps *projectService) DeleteProject(ctx context.Context, deleteReq *DeleteProjectRequest) error {
-	req, err := ps.client.newRequest("DELETE", fmt.Sprintf("projects/%s", deleteReq.Key), nil)
+	req, err := ps.newRequest("DELETE", fmt.Sprintf("projects/%s", url.PathEscape(deleteReq.Key)), nil)
 	if err != nil {
 		return fmt.Errorf("error creating request for deleting project: %w", err)
 	}
@@ -91,13 +92,23 @@ func (ps *projectService) DeleteProject(ctx context.Context, deleteReq *DeletePr
 
 // UpdateProjectRequest contains the fields required to update a project
 type UpdateProjectRequest struct {
-	Key         string `json:"key"`
-	Description string `json:"description,omitempty"`
-	Public      bool   `json:"public,omitempty"`
+	// Key of the project to update
+	Key string `json:"-"`
+	// NewKey moves the project to a different key when it differs from Key
+	NewKey      string `json:"key,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
 }
 
 func (ps *projectService) UpdateProject(ctx context.Context, updateReq *UpdateProjectRequest) (*Project, error) {
-	req, err := ps.client.newRequest("PUT", fmt.Sprintf("projects/%s", updateReq.Key), updateReq)
+	if updateReq.NewKey != "" && updateReq.NewKey != updateReq.Key {
+		// once the project moved it is no longer found at its old key, so
+		// a move that succeeded must not be retried
+		ctx = withoutRetry(ctx)
+	}
+
+	req, err := ps.newRequest("PUT", fmt.Sprintf("projects/%s", url.PathEscape(updateReq.Key)), updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request for updating project: %w", err)
 	}
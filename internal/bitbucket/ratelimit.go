@@ -0,0 +1,46 @@
+package bitbucket
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// limiters holds the rate limiter of every server a client was configured to
+// rate limit requests to, so that all clients talking to a server share it
+var limiters = struct {
+	sync.Mutex
+	m map[string]*rate.Limiter
+}{m: map[string]*rate.Limiter{}}
+
+// sharedLimiter returns the rate limiter of the server at baseURL, updated to
+// the given rate and burst. The last configuration wins when clients disagree
+// about the limit of a server.
+func sharedLimiter(baseURL *url.URL, rps float64, burst int) *rate.Limiter {
+	key := limiterKey(baseURL)
+
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	l, ok := limiters.m[key]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(rps), burst)
+		limiters.m[key] = l
+		return l
+	}
+	if l.Limit() != rate.Limit(rps) {
+		l.SetLimit(rate.Limit(rps))
+	}
+	if l.Burst() != burst {
+		l.SetBurst(burst)
+	}
+	return l
+}
+
+// limiterKey identifies the server at baseURL, ignoring differences in the
+// case of the host and trailing slashes
+func limiterKey(baseURL *url.URL) string {
+	return strings.ToLower(baseURL.Scheme) + "://" + strings.ToLower(baseURL.Host) + strings.TrimSuffix(baseURL.Path, "/")
+}
@@ -0,0 +1,129 @@
+package bitbucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestLimiterKey(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a, b   string
+		same   bool
+	}{
+		"HostCase": {
+			reason: "We should treat hosts differing in case as the same server.",
+			a:      "https://Bitbucket.Example.com",
+			b:      "https://bitbucket.example.com",
+			same:   true,
+		},
+		"TrailingSlash": {
+			reason: "We should treat context paths differing in a trailing slash as the same server.",
+			a:      "https://example.com/bitbucket/",
+			b:      "https://example.com/bitbucket",
+			same:   true,
+		},
+		"ContextPath": {
+			reason: "We should treat different context paths of a host as different servers.",
+			a:      "https://example.com/bitbucket",
+			b:      "https://example.com/other",
+		},
+		"Port": {
+			reason: "We should treat different ports of a host as different servers.",
+			a:      "https://example.com:7990",
+			b:      "https://example.com:8443",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, _ := url.Parse(tc.a)
+			b, _ := url.Parse(tc.b)
+			if got := limiterKey(a) == limiterKey(b); got != tc.same {
+				t.Errorf("\n%s\nlimiterKey(%q) == limiterKey(%q): want %t, got %t", tc.reason, tc.a, tc.b, tc.same, got)
+			}
+		})
+	}
+}
+
+func TestSharedLimiterLastConfigurationWins(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, nil)
+	}))
+	defer srv.Close()
+
+	first := newTestClient(t, srv, WithRateLimit(10, 5))
+	second := newTestClient(t, srv, WithRateLimit(2, 1))
+
+	if first.limiter != second.limiter {
+		t.Fatal("We should share the rate limiter between the clients of a server.")
+	}
+	if got := first.limiter.Limit(); got != rate.Limit(2) {
+		t.Errorf("We should apply the limit configured last to every client of the server, want %v, got %v", rate.Limit(2), got)
+	}
+	if got := first.limiter.Burst(); got != 1 {
+		t.Errorf("We should apply the burst configured last to every client of the server, want 1, got %d", got)
+	}
+
+	unlimited := newTestClient(t, srv)
+	if unlimited.limiter != nil {
+		t.Error("We should not limit a client that did not configure a limit, even when other clients of the server do.")
+	}
+}
+
+func TestRateLimitedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, nil)
+	}))
+	defer srv.Close()
+
+	// a burst of one request every 50ms, so the third request waits 100ms
+	c := newTestClient(t, srv, WithRateLimit(20, 1))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		req, err := c.newRequest(coreAPI, http.MethodGet, "projects/PRJ", nil)
+		if err != nil {
+			t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+		}
+		if err := c.do(context.Background(), req, nil); err != nil {
+			t.Fatalf("c.do(...): unexpected error: %s", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("We should wait for the rate limiter between requests, 3 requests took %s", elapsed)
+	}
+}
+
+func TestRateLimitedRequestCancelled(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		writeJSON(w, http.StatusOK, nil)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, WithRateLimit(1, 1))
+	if !c.limiter.Allow() {
+		t.Fatal("limiter.Allow(): want the burst available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := c.newRequest(coreAPI, http.MethodGet, "projects/PRJ", nil)
+	if err != nil {
+		t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+	}
+	if err := c.do(ctx, req, nil); err == nil {
+		t.Error("c.do(...): want an error waiting past the deadline of the context, got none")
+	}
+	if requests != 0 {
+		t.Errorf("We should not send a request the rate limiter holds back past the deadline, sent %d", requests)
+	}
+}
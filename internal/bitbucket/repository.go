@@ -0,0 +1,195 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const scmGit = "git"
+
+// RepositoryService provides operations around bitbucket repositories
+type RepositoryService interface {
+	GetRepository(context.Context, *GetRepositoryRequest) (*Repository, error)
+	CreateRepository(context.Context, *CreateRepositoryRequest) (*Repository, error)
+	DeleteRepository(context.Context, *DeleteRepositoryRequest) error
+	UpdateRepository(context.Context, *UpdateRepositoryRequest) (*Repository, error)
+	GetDefaultBranch(context.Context, *GetDefaultBranchRequest) (*Branch, error)
+	SetDefaultBranch(context.Context, *SetDefaultBranchRequest) error
+}
+
+type repositoryService struct {
+	service
+}
+
+// Repository represents a Bitbucket Repository
+type Repository struct {
+	Slug        string  `json:"slug"`
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ScmID       string  `json:"scmId"`
+	State       string  `json:"state"`
+	Forkable    bool    `json:"forkable"`
+	Public      bool    `json:"public"`
+	Project     Project `json:"project"`
+	Links       Links   `json:"links"`
+}
+
+// Links contains the links bitbucket returns alongside a resource
+type Links struct {
+	Clone []Link `json:"clone,omitempty"`
+	Self  []Link `json:"self,omitempty"`
+}
+
+// Link is a single named link to a resource
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name,omitempty"`
+}
+
+// Branch represents a branch of a Bitbucket Repository
+type Branch struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// GetRepositoryRequest contains the fields required to fetch a repository
+type GetRepositoryRequest struct {
+	ProjectKey string `json:"-"`
+	Slug       string `json:"-"`
+}
+
+func (rs *repositoryService) GetRepository(ctx context.Context, getReq *GetRepositoryRequest) (*Repository, error) {
+	req, err := rs.newRequest("GET", fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(getReq.ProjectKey), url.PathEscape(getReq.Slug)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting repository: %w", err)
+	}
+
+	r := Repository{}
+	err = rs.client.do(ctx, req, &r)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching repository: %w", err)
+	}
+	return &r, nil
+}
+
+// CreateRepositoryRequest contains the fields required to create a repository
+type CreateRepositoryRequest struct {
+	ProjectKey    string `json:"-"`
+	Name          string `json:"name"`
+	ScmID         string `json:"scmId"`
+	Description   string `json:"description,omitempty"`
+	Forkable      bool   `json:"forkable"`
+	Public        bool   `json:"public"`
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+}
+
+func (rs *repositoryService) CreateRepository(ctx context.Context, createReq *CreateRepositoryRequest) (*Repository, error) {
+	if createReq.ScmID == "" {
+		createReq.ScmID = scmGit
+	}
+
+	req, err := rs.newRequest("POST", fmt.Sprintf("projects/%s/repos", url.PathEscape(createReq.ProjectKey)), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating repository: %w", err)
+	}
+
+	r := Repository{}
+	err = rs.client.do(ctx, req, &r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating repository: %w", err)
+	}
+
+	return &r, nil
+}
+
+// DeleteRepositoryRequest contains the fields required to delete a repository
+type DeleteRepositoryRequest struct {
+	ProjectKey string `json:"-"`
+	Slug       string `json:"-"`
+}
+
+func (rs *repositoryService) DeleteRepository(ctx context.Context, deleteReq *DeleteRepositoryRequest) error {
+	req, err := rs.newRequest("DELETE", fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(deleteReq.ProjectKey), url.PathEscape(deleteReq.Slug)), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting repository: %w", err)
+	}
+
+	err = rs.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting repository: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRepositoryRequest contains the fields required to update a repository
+type UpdateRepositoryRequest struct {
+	ProjectKey  string `json:"-"`
+	Slug        string `json:"-"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+	Forkable    bool   `json:"forkable"`
+	Public      bool   `json:"public"`
+}
+
+func (rs *repositoryService) UpdateRepository(ctx context.Context, updateReq *UpdateRepositoryRequest) (*Repository, error) {
+	req, err := rs.newRequest("PUT", fmt.Sprintf("projects/%s/repos/%s", url.PathEscape(updateReq.ProjectKey), url.PathEscape(updateReq.Slug)), updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for updating repository: %w", err)
+	}
+
+	r := Repository{}
+	err = rs.client.do(ctx, req, &r)
+	if err != nil {
+		return nil, fmt.Errorf("error updating repository: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetDefaultBranchRequest contains the fields required to fetch the default
+// branch of a repository
+type GetDefaultBranchRequest struct {
+	ProjectKey string `json:"-"`
+	Slug       string `json:"-"`
+}
+
+func (rs *repositoryService) GetDefaultBranch(ctx context.Context, getReq *GetDefaultBranchRequest) (*Branch, error) {
+	req, err := rs.newRequest("GET", fmt.Sprintf("projects/%s/repos/%s/branches/default", url.PathEscape(getReq.ProjectKey), url.PathEscape(getReq.Slug)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting default branch: %w", err)
+	}
+
+	b := Branch{}
+	err = rs.client.do(ctx, req, &b)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching default branch: %w", err)
+	}
+	return &b, nil
+}
+
+// SetDefaultBranchRequest contains the fields required to change the default
+// branch of a repository
+type SetDefaultBranchRequest struct {
+	ProjectKey string `json:"-"`
+	Slug       string `json:"-"`
+	// ID is the fully qualified name of the branch, e.g. refs/heads/main
+	ID string `json:"id"`
+}
+
+func (rs *repositoryService) SetDefaultBranch(ctx context.Context, setReq *SetDefaultBranchRequest) error {
+	req, err := rs.newRequest("PUT", fmt.Sprintf("projects/%s/repos/%s/branches/default", url.PathEscape(setReq.ProjectKey), url.PathEscape(setReq.Slug)), setReq)
+	if err != nil {
+		return fmt.Errorf("error creating request for setting default branch: %w", err)
+	}
+
+	err = rs.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error setting default branch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how requests that failed transiently are retried.
+// Only idempotent requests are retried, after connection errors or a 429,
+// 502, 503 or 504 response.
+type RetryPolicy struct {
+	// MaxRetries is how often a request is retried, zero disabling retries
+	MaxRetries int
+	// MinBackoff is the wait before the first retry, doubled with every
+	// further retry
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between retries. A server asking to wait
+	// longer through Retry-After is not retried.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a client unless configured
+// otherwise
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+// errNoRewind is returned when a request has to be retried but its body
+// cannot be read again
+var errNoRewind = errors.New("cannot rewind request body for retry")
+
+type noRetryKey struct{}
+
+// withoutRetry marks the requests made with ctx as not to be retried, which
+// is needed for requests that are not idempotent despite their method
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// retryable reports whether req may be sent again
+func retryable(req *http.Request) bool {
+	if noRetry, _ := req.Context().Value(noRetryKey{}).(bool); noRetry {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryWait returns how long to wait before retrying req, which was answered
+// with res or failed with err on the given attempt, and whether to retry at
+// all
+func (p RetryPolicy) retryWait(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxRetries || !retryable(req) {
+		return 0, false
+	}
+
+	ctx := req.Context()
+	switch {
+	case err != nil:
+		// errors caused by the context are not transient
+		if ctx.Err() != nil {
+			return 0, false
+		}
+	case res.StatusCode == http.StatusTooManyRequests,
+		res.StatusCode == http.StatusBadGateway,
+		res.StatusCode == http.StatusServiceUnavailable,
+		res.StatusCode == http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	wait := p.backoff(attempt)
+	if ra, ok := retryAfter(res); ok {
+		if ra > p.MaxBackoff {
+			return 0, false
+		}
+		wait = ra
+	}
+
+	// don't start waiting for a retry there is no time left for
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// backoff returns the jittered exponential backoff of the given attempt,
+// which is randomly chosen from the upper half of the exponential delay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 0; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // jitter needs no cryptographic randomness
+}
+
+// retryAfter parses the Retry-After header of res, which holds either a
+// number of seconds or an HTTP date
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// rewind returns a copy of req that can be sent again
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errNoRewind
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// discard drains and closes the body of a response that is not used, so its
+// connection can be reused
+func discard(res *http.Response) {
+	if res == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+}
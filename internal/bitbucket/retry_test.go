@@ -0,0 +1,325 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fastRetries retries quickly enough for tests
+var fastRetries = RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+// statusServer answers the requests it receives with the given statuses in
+// turn, and with 200 once they run out. It records the methods and bodies of
+// the requests.
+type statusServer struct {
+	statuses   []int
+	retryAfter string
+	methods    []string
+	bodies     []string
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	s.methods = append(s.methods, r.Method)
+	s.bodies = append(s.bodies, string(b))
+
+	if len(s.statuses) == 0 {
+		writeJSON(w, http.StatusOK, AccessToken{ID: "1"})
+		return
+	}
+	status := s.statuses[0]
+	s.statuses = s.statuses[1:]
+	if s.retryAfter != "" {
+		w.Header().Set("Retry-After", s.retryAfter)
+	}
+	writeJSON(w, status, nil)
+}
+
+func TestRetry(t *testing.T) {
+	type want struct {
+		attempts int
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason     string
+		method     string
+		statuses   []int
+		retryAfter string
+		want       want
+	}{
+		"TooManyRequests": {
+			reason:   "We should retry a request the server was too busy to answer.",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusTooManyRequests},
+			want:     want{attempts: 2},
+		},
+		"BadGateway": {
+			reason:   "We should retry a request a gateway failed to pass on.",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway},
+			want:     want{attempts: 2},
+		},
+		"ServiceUnavailable": {
+			reason:   "We should retry a request to a server that is unavailable.",
+			method:   http.MethodDelete,
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			want:     want{attempts: 3},
+		},
+		"GatewayTimeout": {
+			reason:   "We should retry a request a gateway timed out on.",
+			method:   http.MethodPut,
+			statuses: []int{http.StatusGatewayTimeout},
+			want:     want{attempts: 2},
+		},
+		"InternalServerError": {
+			reason:   "We should not retry a request the server failed to handle.",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusInternalServerError},
+			want:     want{attempts: 1, err: ErrServer},
+		},
+		"NotFound": {
+			reason:   "We should not retry a request for something that does not exist.",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusNotFound},
+			want:     want{attempts: 1, err: ErrNotFound},
+		},
+		"Post": {
+			reason:   "We should not retry a request that is not idempotent.",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusServiceUnavailable},
+			want:     want{attempts: 1, err: ErrServer},
+		},
+		"RetriesExhausted": {
+			reason:   "We should give up once the request was retried as often as the policy allows.",
+			method:   http.MethodGet,
+			statuses: []int{503, 503, 503, 503, 503},
+			want:     want{attempts: 4, err: ErrServer},
+		},
+		"RetryAfter": {
+			reason:     "We should retry a request the server asks to retry within the maximum backoff.",
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusTooManyRequests},
+			retryAfter: "0",
+			want:       want{attempts: 2},
+		},
+		"RetryAfterTooLong": {
+			reason:     "We should not retry a request the server asks to retry later than the maximum backoff.",
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusServiceUnavailable},
+			retryAfter: "60",
+			want:       want{attempts: 1, err: ErrServer},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ss := &statusServer{statuses: tc.statuses, retryAfter: tc.retryAfter}
+			srv := httptest.NewServer(ss)
+			defer srv.Close()
+
+			c := newTestClient(t, srv, WithRetryPolicy(fastRetries))
+			req, err := c.newRequest(coreAPI, tc.method, "projects/PRJ", nil)
+			if err != nil {
+				t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+			}
+			err = c.do(context.Background(), req, nil)
+			if !errors.Is(err, tc.want.err) {
+				t.Errorf("\n%s\nc.do(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.attempts, len(ss.methods)); diff != "" {
+				t.Errorf("\n%s\nc.do(...): -want attempts, +got attempts:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateAccessTokenNotRetried(t *testing.T) {
+	ss := &statusServer{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+
+	c := newTestClient(t, srv, WithRetryPolicy(fastRetries))
+	_, err := c.AccessTokens.CreateAccessToken(context.Background(), &CreateAccessTokenRequest{TokenScope: TokenScope{User: "admin"}, Name: "rotated"})
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("CreateAccessToken(...): want error %v, got %v", ErrServer, err)
+	}
+	if diff := cmp.Diff([]string{http.MethodPut}, ss.methods); diff != "" {
+		t.Errorf("We should not retry creating an access token, as every attempt mints another token.\n-want, +got:\n%s\n", diff)
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	ss := &statusServer{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	c := newTestClient(t, srv, WithRetryPolicy(RetryPolicy{MaxRetries: 3, MinBackoff: time.Second, MaxBackoff: time.Second}))
+	req, err := c.newRequest(coreAPI, http.MethodGet, "projects/PRJ", nil)
+	if err != nil {
+		t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+	}
+
+	start := time.Now()
+	err = c.do(ctx, req, nil)
+	if !errors.Is(err, ErrServer) {
+		t.Errorf("c.do(...): want error %v, got %v", ErrServer, err)
+	}
+	if len(ss.methods) != 1 {
+		t.Errorf("c.do(...): want 1 attempt, got %d", len(ss.methods))
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("We should not wait for a retry past the deadline of the context, waited %s", elapsed)
+	}
+}
+
+func TestRetryRewindsBody(t *testing.T) {
+	ss := &statusServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+
+	c := newTestClient(t, srv, WithRetryPolicy(fastRetries))
+	req, err := c.newRequest(coreAPI, http.MethodPut, "projects/PRJ", UpdateProjectRequest{Key: "PRJ", Name: "Project"})
+	if err != nil {
+		t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+	}
+	if err := c.do(context.Background(), req, nil); err != nil {
+		t.Fatalf("c.do(...): unexpected error: %s", err)
+	}
+
+	if len(ss.bodies) != 3 || ss.bodies[0] == "" {
+		t.Fatalf("c.do(...): want 3 attempts with a body, got %q", ss.bodies)
+	}
+	for _, b := range ss.bodies[1:] {
+		if diff := cmp.Diff(ss.bodies[0], b); diff != "" {
+			t.Errorf("We should send the same body with every retry.\n-want, +got:\n%s\n", diff)
+		}
+	}
+}
+
+func TestRetryCannotRewind(t *testing.T) {
+	ss := &statusServer{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+
+	c := newTestClient(t, srv, WithRetryPolicy(fastRetries))
+	req, err := c.newRequest(coreAPI, http.MethodPut, "projects/PRJ", nil)
+	if err != nil {
+		t.Fatalf("c.newRequest(...): unexpected error: %s", err)
+	}
+	req.Body = io.NopCloser(strings.NewReader(`{"key":"PRJ"}`))
+	req.GetBody = nil
+
+	if err := c.do(context.Background(), req, nil); !errors.Is(err, errNoRewind) {
+		t.Errorf("c.do(...): want error %v, got %v", errNoRewind, err)
+	}
+	if len(ss.methods) != 1 {
+		t.Errorf("We should not retry a request whose body cannot be sent again, got %d attempts", len(ss.methods))
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	type want struct {
+		min, max time.Duration
+		ok       bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		header string
+		want   want
+	}{
+		"Missing": {
+			reason: "We should not read a wait from a response without Retry-After.",
+		},
+		"Seconds": {
+			reason: "We should read a Retry-After holding a number of seconds.",
+			header: "2",
+			want:   want{min: 2 * time.Second, max: 2 * time.Second, ok: true},
+		},
+		"HTTPDate": {
+			reason: "We should read a Retry-After holding an HTTP date as the time until that date.",
+			header: time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat),
+			want:   want{min: 8 * time.Second, max: 10 * time.Second, ok: true},
+		},
+		"PastHTTPDate": {
+			reason: "We should not wait for an HTTP date that passed already.",
+			header: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			want:   want{ok: true},
+		},
+		"Negative": {
+			reason: "We should ignore a negative number of seconds.",
+			header: "-1",
+		},
+		"Invalid": {
+			reason: "We should ignore a Retry-After that is neither seconds nor an HTTP date.",
+			header: "soon",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				res.Header.Set("Retry-After", tc.header)
+			}
+			got, ok := retryAfter(res)
+			if ok != tc.want.ok {
+				t.Errorf("\n%s\nretryAfter(...): want ok %t, got %t", tc.reason, tc.want.ok, ok)
+			}
+			if got < tc.want.min || got > tc.want.max {
+				t.Errorf("\n%s\nretryAfter(...): want a wait within [%s, %s], got %s", tc.reason, tc.want.min, tc.want.max, got)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 5, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	cases := map[string]struct {
+		reason   string
+		attempt  int
+		min, max time.Duration
+	}{
+		"FirstRetry": {
+			reason:  "We should wait up to the minimum backoff before the first retry.",
+			attempt: 0,
+			min:     50 * time.Millisecond,
+			max:     100 * time.Millisecond,
+		},
+		"ThirdRetry": {
+			reason:  "We should double the backoff with every further retry.",
+			attempt: 2,
+			min:     200 * time.Millisecond,
+			max:     400 * time.Millisecond,
+		},
+		"Capped": {
+			reason:  "We should not wait longer than the maximum backoff.",
+			attempt: 10,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := p.backoff(tc.attempt); got < tc.min || got > tc.max {
+					t.Fatalf("\n%s\np.backoff(%d): want a wait within [%s, %s], got %s", tc.reason, tc.attempt, tc.min, tc.max, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// UserService provides operations around bitbucket users
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-system-maintenance/#api-api-latest-users-get
+type UserService interface {
+	FindUser(context.Context, *FindUserRequest) (*User, error)
+}
+
+type userService struct {
+	service
+}
+
+// FindUserRequest contains the fields required to find a user
+type FindUserRequest struct {
+	// Name of the user, matched case insensitively
+	Name string `json:"-"`
+}
+
+// FindUser looks the user up among the users matching its name. The filter
+// matches substrings, so the user has to be looked for in the results.
+func (us *userService) FindUser(ctx context.Context, findReq *FindUserRequest) (*User, error) {
+	it := newPageIterator[User](&us.service, fmt.Sprintf("users?%s", url.Values{"filter": {findReq.Name}}.Encode()))
+	for it.Next(ctx) {
+		u := it.Value()
+		if strings.EqualFold(u.Name, findReq.Name) {
+			return &u, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("error fetching users: %w", err)
+	}
+	return nil, fmt.Errorf("error fetching user: %w", ErrNotFound)
+}
@@ -0,0 +1,145 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// WebhookService provides operations around the webhooks of projects and
+// repositories
+// API Docs: https://developer.atlassian.com/server/bitbucket/rest/v805/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-webhooks-get
+type WebhookService interface {
+	GetWebhook(context.Context, *GetWebhookRequest) (*Webhook, error)
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	UpdateWebhook(context.Context, *UpdateWebhookRequest) (*Webhook, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) error
+}
+
+type webhookService struct {
+	service
+}
+
+// Webhook represents a Bitbucket webhook
+type Webhook struct {
+	ID            int                  `json:"id"`
+	Name          string               `json:"name"`
+	URL           string               `json:"url"`
+	Events        []string             `json:"events"`
+	Active        bool                 `json:"active"`
+	Configuration WebhookConfiguration `json:"configuration"`
+}
+
+// WebhookConfiguration holds the optional settings of a webhook
+type WebhookConfiguration struct {
+	// Secret is used to sign the payloads sent to the webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// GetWebhookRequest contains the fields required to fetch a webhook
+type GetWebhookRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project webhooks
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (ws *webhookService) GetWebhook(ctx context.Context, getReq *GetWebhookRequest) (*Webhook, error) {
+	req, err := ws.newRequest("GET", fmt.Sprintf("%s/%d", webhooksPath(getReq.ProjectKey, getReq.RepositorySlug), getReq.ID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for getting webhook: %w", err)
+	}
+
+	w := Webhook{}
+	err = ws.client.do(ctx, req, &w)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching webhook: %w", err)
+	}
+	return &w, nil
+}
+
+// CreateWebhookRequest contains the fields required to create a webhook
+type CreateWebhookRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project webhooks
+	RepositorySlug string               `json:"-"`
+	Name           string               `json:"name"`
+	URL            string               `json:"url"`
+	Events         []string             `json:"events"`
+	Active         bool                 `json:"active"`
+	Configuration  WebhookConfiguration `json:"configuration"`
+}
+
+func (ws *webhookService) CreateWebhook(ctx context.Context, createReq *CreateWebhookRequest) (*Webhook, error) {
+	req, err := ws.newRequest("POST", webhooksPath(createReq.ProjectKey, createReq.RepositorySlug), createReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for creating webhook: %w", err)
+	}
+
+	w := Webhook{}
+	err = ws.client.do(ctx, req, &w)
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	return &w, nil
+}
+
+// UpdateWebhookRequest contains the fields required to update a webhook
+type UpdateWebhookRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project webhooks
+	RepositorySlug string               `json:"-"`
+	ID             int                  `json:"-"`
+	Name           string               `json:"name"`
+	URL            string               `json:"url"`
+	Events         []string             `json:"events"`
+	Active         bool                 `json:"active"`
+	Configuration  WebhookConfiguration `json:"configuration"`
+}
+
+func (ws *webhookService) UpdateWebhook(ctx context.Context, updateReq *UpdateWebhookRequest) (*Webhook, error) {
+	req, err := ws.newRequest("PUT", fmt.Sprintf("%s/%d", webhooksPath(updateReq.ProjectKey, updateReq.RepositorySlug), updateReq.ID), updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for updating webhook: %w", err)
+	}
+
+	w := Webhook{}
+	err = ws.client.do(ctx, req, &w)
+	if err != nil {
+		return nil, fmt.Errorf("error updating webhook: %w", err)
+	}
+
+	return &w, nil
+}
+
+// DeleteWebhookRequest contains the fields required to delete a webhook
+type DeleteWebhookRequest struct {
+	ProjectKey string `json:"-"`
+	// RepositorySlug is empty for project webhooks
+	RepositorySlug string `json:"-"`
+	ID             int    `json:"-"`
+}
+
+func (ws *webhookService) DeleteWebhook(ctx context.Context, deleteReq *DeleteWebhookRequest) error {
+	req, err := ws.newRequest("DELETE", fmt.Sprintf("%s/%d", webhooksPath(deleteReq.ProjectKey, deleteReq.RepositorySlug), deleteReq.ID), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request for deleting webhook: %w", err)
+	}
+
+	err = ws.client.do(ctx, req, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+
+	return nil
+}
+
+// webhooksPath builds the path of the webhooks of a project, or of a
+// repository when slug is set
+func webhooksPath(projectKey string, slug string) string {
+	if slug == "" {
+		return fmt.Sprintf("projects/%s/webhooks", url.PathEscape(projectKey))
+	}
+	return fmt.Sprintf("projects/%s/repos/%s/webhooks", url.PathEscape(projectKey), url.PathEscape(slug))
+}
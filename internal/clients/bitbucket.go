@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clients builds bitbucket clients from the ProviderConfig referenced
+// by a managed resource.
+package clients
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+const (
+	errGetPC      = "cannot get ProviderConfig"
+	errGetCreds   = "cannot get credentials"
+	errParseCreds = "cannot parse credentials"
+
+	errClientOptions = "cannot configure client"
+	errNewClient     = "cannot create bitbucket client"
+	errGetObject     = "cannot get %s/%s"
+)
+
+// GetClient returns a bitbucket client for the base URL and credentials of the
+// ProviderConfig referenced by the supplied managed resource. Clients are
+// cached, and only built and checked against the server again once the
+// ProviderConfig or the Secrets and ConfigMaps it references change.
+func GetClient(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error) {
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cd := pc.Spec.Credentials
+	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, kube, cd.CommonCredentialSelectors)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+
+	key, err := clientCacheKey(ctx, kube, pc, data)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := cachedClient(pc, key); ok {
+		return c, nil
+	}
+
+	creds, err := ParseCredentials(pc.Spec.AuthType, data)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseCreds)
+	}
+
+	opts, err := ClientOptions(ctx, kube, pc)
+	if err != nil {
+		return nil, errors.Wrap(err, errClientOptions)
+	}
+
+	c, err := NewClient(ctx, pc.Spec.BaseURL, creds, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	cacheClient(pc, key, c)
+	return c, nil
+}
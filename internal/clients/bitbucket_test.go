@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+const credentials = `{"username":"admin","password":"secret"}`
+
+// providerConfigOf returns a ProviderConfig of the server at baseURL reading
+// its credentials from the bitbucket-creds Secret. Its UID is unique to the
+// test, so it does not share cached clients with other tests.
+func providerConfigOf(t *testing.T, baseURL string) *v1alpha1.ProviderConfig {
+	return &v1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "bitbucket", UID: types.UID(t.Name())},
+		Spec: v1alpha1.ProviderConfigSpec{
+			BaseURL: baseURL,
+			Credentials: v1alpha1.ProviderCredentials{
+				Source:                    xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: selectorRef("bitbucket-creds", "credentials")},
+			},
+		},
+	}
+}
+
+func managedResource() *fake.Managed {
+	return &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "bitbucket"}}}
+}
+
+func newProviderConfigKube(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+	return kubefake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func TestGetClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); u != "admin" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		emptyPage(w, r)
+	}))
+	defer srv.Close()
+
+	type want struct {
+		err error
+		// is is the sentinel the error matches, for errors whose message
+		// depends on the server
+		is error
+	}
+
+	cases := map[string]struct {
+		reason string
+		// baseURL defaults to the URL of the test server
+		baseURL string
+		noPC    bool
+		creds   string
+		want    want
+	}{
+		"Success": {
+			reason: "We should return a client of the server authenticating with the configured credentials.",
+			creds:  credentials,
+		},
+		"ProviderConfigNotFound": {
+			reason: "We should return an error when the ProviderConfig does not exist.",
+			noPC:   true,
+			creds:  credentials,
+			want:   want{err: errors.Wrap(kerrors.NewNotFound(schema.GroupResource{Group: v1alpha1.Group, Resource: "providerconfigs"}, "bitbucket"), errGetPC)},
+		},
+		"MalformedCredentials": {
+			reason: "We should return an error when the credentials cannot be parsed.",
+			creds:  `{"username":"admin"}`,
+			want:   want{err: errors.Wrap(errors.New(errNoSecret), errParseCreds)},
+		},
+		"BadURL": {
+			reason:  "We should return an error, rather than exit, when the base URL cannot be parsed.",
+			baseURL: "http://bitbucket example.org",
+			creds:   credentials,
+			want:    want{err: errors.Wrap(errors.New(`parse "http://bitbucket example.org": invalid character " " in host name`), errNewClient)},
+		},
+		"BadCredentials": {
+			reason: "We should return an error, rather than exit, when the server rejects the credentials.",
+			creds:  `{"username":"admin","password":"wrong"}`,
+			want:   want{is: bitbucket.ErrPermission},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			baseURL := tc.baseURL
+			if baseURL == "" {
+				baseURL = srv.URL
+			}
+			objs := []client.Object{secretOf("bitbucket-creds", map[string][]byte{"credentials": []byte(tc.creds)})}
+			if !tc.noPC {
+				objs = append(objs, providerConfigOf(t, baseURL))
+			}
+
+			c, err := GetClient(context.Background(), newProviderConfigKube(objs...), managedResource())
+			if tc.want.is != nil {
+				if !errors.Is(err, tc.want.is) {
+					t.Errorf("\n%s\nGetClient(...): want error matching %v, got %v", tc.reason, tc.want.is, err)
+				}
+			} else if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetClient(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err == nil && c == nil {
+				t.Errorf("\n%s\nGetClient(...): want a client, got none", tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+// cacheKey identifies the configuration a client was built from. A client is
+// reused for as long as neither the ProviderConfig nor the Secrets and
+// ConfigMaps it references change.
+type cacheKey struct {
+	generation int64
+
+	// versions are the resource versions of the objects the ProviderConfig
+	// references, or a digest of the credentials when they are not read from
+	// a Secret
+	versions string
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	client *bitbucket.Client
+}
+
+// clientCache holds the last client built for each ProviderConfig, by UID
+var clientCache = struct {
+	sync.Mutex
+	m map[types.UID]cacheEntry
+}{m: map[types.UID]cacheEntry{}}
+
+// cachedClient returns the client cached for pc if it was built from key.
+func cachedClient(pc *apisv1alpha1.ProviderConfig, key cacheKey) (*bitbucket.Client, bool) {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	e, ok := clientCache.m[pc.GetUID()]
+	if !ok || e.key != key {
+		return nil, false
+	}
+	return e.client, true
+}
+
+// cacheClient caches c as the client of pc, replacing the client built from a
+// previous configuration. Clients of ProviderConfigs being deleted are not
+// cached, as nothing would evict them.
+func cacheClient(pc *apisv1alpha1.ProviderConfig, key cacheKey, c *bitbucket.Client) {
+	if meta.WasDeleted(pc) {
+		return
+	}
+
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	clientCache.m[pc.GetUID()] = cacheEntry{key: key, client: c}
+}
+
+// EvictClient drops the client cached for the ProviderConfig with the given
+// UID. It is called once the ProviderConfig is deleted.
+func EvictClient(uid types.UID) {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	delete(clientCache.m, uid)
+}
+
+// clientCacheKey returns the key of the configuration pc and data, its
+// credentials, currently describe.
+func clientCacheKey(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig, data []byte) (cacheKey, error) {
+	versions := []string{}
+
+	cd := pc.Spec.Credentials
+	if cd.Source == xpv1.CredentialsSourceSecret && cd.SecretRef != nil {
+		v, err := resourceVersion(ctx, kube, &corev1.Secret{}, cd.SecretRef.Namespace, cd.SecretRef.Name)
+		if err != nil {
+			return cacheKey{}, err
+		}
+		versions = append(versions, v)
+	} else {
+		sum := sha256.Sum256(data)
+		versions = append(versions, hex.EncodeToString(sum[:]))
+	}
+
+	if t := pc.Spec.TLS; t != nil {
+		if b := t.CABundle; b != nil && b.SecretRef != nil {
+			v, err := resourceVersion(ctx, kube, &corev1.Secret{}, b.SecretRef.Namespace, b.SecretRef.Name)
+			if err != nil {
+				return cacheKey{}, err
+			}
+			versions = append(versions, v)
+		}
+		if b := t.CABundle; b != nil && b.ConfigMapRef != nil {
+			v, err := resourceVersion(ctx, kube, &corev1.ConfigMap{}, b.ConfigMapRef.Namespace, b.ConfigMapRef.Name)
+			if err != nil {
+				return cacheKey{}, err
+			}
+			versions = append(versions, v)
+		}
+		if cc := t.ClientCertificate; cc != nil {
+			for _, ref := range []xpv1.SecretKeySelector{cc.CertSecretRef, cc.KeySecretRef} {
+				v, err := resourceVersion(ctx, kube, &corev1.Secret{}, ref.Namespace, ref.Name)
+				if err != nil {
+					return cacheKey{}, err
+				}
+				versions = append(versions, v)
+			}
+		}
+	}
+
+	return cacheKey{generation: pc.GetGeneration(), versions: strings.Join(versions, "/")}, nil
+}
+
+// resourceVersion returns the resource version of the named object.
+func resourceVersion(ctx context.Context, kube client.Client, obj client.Object, namespace, name string) (string, error) {
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return "", errors.Wrapf(err, errGetObject, namespace, name)
+	}
+	return obj.GetResourceVersion(), nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+func TestGetClientCache(t *testing.T) {
+	pings := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings++
+		emptyPage(w, r)
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		reason string
+		// change is made between getting the client twice
+		change  func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) error
+		rebuilt bool
+	}{
+		"Hit": {
+			reason: "We should reuse the client of a ProviderConfig whose configuration did not change.",
+			change: func(context.Context, client.Client, *v1alpha1.ProviderConfig) error { return nil },
+		},
+		"ProviderConfigChanged": {
+			reason: "We should build another client once the spec of the ProviderConfig changed.",
+			change: func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) error {
+				pc.SetGeneration(pc.GetGeneration() + 1)
+				return kube.Update(ctx, pc)
+			},
+			rebuilt: true,
+		},
+		"CredentialsChanged": {
+			reason: "We should build another client once the credentials Secret changed.",
+			change: func(ctx context.Context, kube client.Client, _ *v1alpha1.ProviderConfig) error {
+				s := &corev1.Secret{}
+				if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "bitbucket-creds"}, s); err != nil {
+					return err
+				}
+				s.Data["credentials"] = []byte(`{"username":"admin","password":"rotated"}`)
+				return kube.Update(ctx, s)
+			},
+			rebuilt: true,
+		},
+		"CABundleChanged": {
+			reason: "We should build another client once the ConfigMap holding the CA bundle changed.",
+			change: func(ctx context.Context, kube client.Client, _ *v1alpha1.ProviderConfig) error {
+				cm := &corev1.ConfigMap{}
+				if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "ca"}, cm); err != nil {
+					return err
+				}
+				cm.Data["other.crt"] = cm.Data["ca.crt"]
+				return kube.Update(ctx, cm)
+			},
+			rebuilt: true,
+		},
+		"Evicted": {
+			reason: "We should build another client once the client of the ProviderConfig was evicted.",
+			change: func(_ context.Context, _ client.Client, pc *v1alpha1.ProviderConfig) error {
+				EvictClient(pc.GetUID())
+				return nil
+			},
+			rebuilt: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			pc := providerConfigOf(t, srv.URL)
+			pc.SetGeneration(1)
+			pc.Spec.TLS = &v1alpha1.TLSConfig{CABundle: &v1alpha1.CABundleSource{
+				ConfigMapRef: &v1alpha1.ConfigMapKeySelector{Name: "ca", Namespace: namespace, Key: "ca.crt"},
+			}}
+			kube := newProviderConfigKube(
+				pc,
+				secretOf("bitbucket-creds", map[string][]byte{"credentials": []byte(credentials)}),
+				configMapOf("ca", map[string]string{"ca.crt": string(certPEM(srv))}),
+			)
+			defer EvictClient(pc.GetUID())
+
+			pings = 0
+			first, err := GetClient(ctx, kube, managedResource())
+			if err != nil {
+				t.Fatalf("GetClient(...): unexpected error: %s", err)
+			}
+
+			if err := kube.Get(ctx, types.NamespacedName{Name: pc.GetName()}, pc); err != nil {
+				t.Fatalf("kube.Get(...): unexpected error: %s", err)
+			}
+			if err := tc.change(ctx, kube, pc); err != nil {
+				t.Fatalf("change(...): unexpected error: %s", err)
+			}
+
+			second, err := GetClient(ctx, kube, managedResource())
+			if err != nil {
+				t.Fatalf("GetClient(...): unexpected error: %s", err)
+			}
+
+			if rebuilt := first != second; rebuilt != tc.rebuilt {
+				t.Errorf("\n%s\nGetClient(...): want another client %t, got %t", tc.reason, tc.rebuilt, rebuilt)
+			}
+			wantPings := 1
+			if tc.rebuilt {
+				wantPings = 2
+			}
+			if pings != wantPings {
+				t.Errorf("\n%s\nGetClient(...): want the server pinged %d times, got %d", tc.reason, wantPings, pings)
+			}
+		})
+	}
+}
+
+func TestCacheClientBeingDeleted(t *testing.T) {
+	now := metav1.Now()
+	pc := &v1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{UID: types.UID(t.Name()), DeletionTimestamp: &now}}
+	key := cacheKey{generation: 1, versions: "1"}
+
+	cacheClient(pc, key, &bitbucket.Client{})
+	if _, ok := cachedClient(pc, key); ok {
+		t.Error("We should not cache the client of a ProviderConfig being deleted, as nothing would evict it.")
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+const (
+	errDecodeCreds    = "cannot decode base64 credentials"
+	errMalformedCreds = "credentials are not of the form username:password"
+	errNoToken        = "credentials hold no access token"
+	errDecodeJSON     = "cannot decode JSON credentials"
+	errTokenPassword  = "credentials must not hold both a password and a token"
+	errNoSecret       = "credentials must hold either a token, or a username and a password"
+)
+
+// credentialsJSON is the structured format of the credentials.
+type credentialsJSON struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// Credentials authenticate the provider against bitbucket.
+type Credentials struct {
+	Username string
+	// Password is either the password of the user or one of its HTTP access
+	// tokens.
+	Password string
+	// Token is an HTTP access token sent as bearer token. It takes precedence
+	// over the password.
+	Token string
+
+	// structured tells whether the credentials were read from JSON, which
+	// is the format they are encoded in again.
+	structured bool
+}
+
+// ParseCredentials parses the credentials of a ProviderConfig. They are
+// either a JSON document holding a username and password, or a token and
+// optionally the username it belongs to, or one of the legacy formats
+// selected by auth. Legacy Basic credentials are the base64 encoding of
+// username:password, legacy Bearer credentials are an HTTP access token.
+func ParseCredentials(auth apisv1alpha1.AuthType, data []byte) (Credentials, error) {
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSONCredentials(trimmed)
+	}
+
+	if auth == apisv1alpha1.AuthTypeBearer {
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return Credentials{}, errors.New(errNoToken)
+		}
+		return Credentials{Token: token}, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return Credentials{}, errors.Wrap(err, errDecodeCreds)
+	}
+	username, password, ok := strings.Cut(string(raw), ":")
+	if !ok || username == "" {
+		return Credentials{}, errors.New(errMalformedCreds)
+	}
+	return Credentials{Username: username, Password: password}, nil
+}
+
+// parseJSONCredentials parses and validates the structured format of the
+// credentials.
+func parseJSONCredentials(data []byte) (Credentials, error) {
+	j := credentialsJSON{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&j); err != nil {
+		return Credentials{}, errors.Wrap(err, errDecodeJSON)
+	}
+
+	switch {
+	case j.Token != "" && j.Password != "":
+		return Credentials{}, errors.New(errTokenPassword)
+	case j.Token == "" && (j.Username == "" || j.Password == ""):
+		return Credentials{}, errors.New(errNoSecret)
+	}
+
+	return Credentials{Username: j.Username, Password: j.Password, Token: j.Token, structured: true}, nil
+}
+
+// Encode returns the credentials in the format they were parsed from.
+func (c Credentials) Encode() []byte {
+	if c.structured {
+		// marshaling a struct of strings cannot fail
+		data, _ := json.Marshal(credentialsJSON{Username: c.Username, Password: c.Password, Token: c.Token})
+		return data
+	}
+	if c.Token != "" {
+		return []byte(c.Token)
+	}
+	return []byte(c.basic())
+}
+
+// basic returns the base64 encoding of username:password.
+func (c Credentials) basic() string {
+	return base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+}
+
+// NewClient creates a bitbucket client for baseURL authenticating with creds.
+func NewClient(ctx context.Context, baseURL string, creds Credentials, opts ...bitbucket.Option) (*bitbucket.Client, error) {
+	auth := bitbucket.WithAuth(bitbucket.AuthBasic, creds.basic())
+	if creds.Token != "" {
+		auth = bitbucket.WithAuth(bitbucket.AuthBearer, creds.Token)
+	}
+	return bitbucket.New(ctx, baseURL, append([]bitbucket.Option{auth}, opts...)...)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+const (
+	errParseProxyURL     = "cannot parse proxy URL"
+	errGetCABundle       = "cannot get CA bundle"
+	errNoCABundleSource  = "CA bundle selects neither a Secret nor a ConfigMap"
+	errNoCertificates    = "CA bundle holds no PEM encoded certificates"
+	errGetClientCert     = "cannot get client certificate"
+	errGetClientKey      = "cannot get client certificate key"
+	errLoadClientCert    = "cannot load client certificate"
+	errGetSecret         = "cannot get Secret"
+	errGetConfigMap      = "cannot get ConfigMap"
+	errSecretKeyEmpty    = "selected Secret key is empty"
+	errConfigMapKeyEmpty = "selected ConfigMap key is empty"
+)
+
+// ClientOptions returns the options of the bitbucket client the ProviderConfig
+// configures, reading the Secrets and ConfigMaps it references.
+func ClientOptions(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig) ([]bitbucket.Option, error) {
+	opts := []bitbucket.Option{}
+
+	if p := pc.Spec.Proxy; p != nil {
+		u, err := url.Parse(p.URL)
+		if err != nil {
+			return nil, errors.Wrap(err, errParseProxyURL)
+		}
+		opts = append(opts, bitbucket.WithProxy(u, p.NoProxy))
+	}
+
+	if len(pc.Spec.Headers) > 0 {
+		opts = append(opts, bitbucket.WithHeaders(pc.Spec.Headers))
+	}
+
+	if pc.Spec.Retry != nil {
+		opts = append(opts, bitbucket.WithRetryPolicy(retryPolicy(pc.Spec.Retry)))
+	}
+
+	if rl := pc.Spec.RateLimit; rl != nil {
+		burst := rl.Burst
+		if burst == 0 {
+			burst = rl.RequestsPerSecond
+		}
+		opts = append(opts, bitbucket.WithRateLimit(float64(rl.RequestsPerSecond), burst))
+	}
+
+	if pc.Spec.TLS != nil {
+		cfg, err := tlsConfig(ctx, kube, pc.Spec.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, bitbucket.WithTLSConfig(cfg))
+	}
+
+	return opts, nil
+}
+
+// retryPolicy returns the retry policy r configures, falling back to the
+// defaults of the client for what it omits.
+func retryPolicy(r *apisv1alpha1.RetryPolicy) bitbucket.RetryPolicy {
+	p := bitbucket.DefaultRetryPolicy
+	if r.MaxRetries != nil {
+		p.MaxRetries = *r.MaxRetries
+	}
+	if r.MinBackoff != nil {
+		p.MinBackoff = r.MinBackoff.Duration
+	}
+	if r.MaxBackoff != nil {
+		p.MaxBackoff = r.MaxBackoff.Duration
+	}
+	return p
+}
+
+// tlsConfig builds the TLS configuration of the connection to the server.
+func tlsConfig(ctx context.Context, kube client.Client, t *apisv1alpha1.TLSConfig) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec // explicitly requested by the ProviderConfig
+	}
+
+	if t.CABundle != nil {
+		bundle, err := caBundle(ctx, kube, t.CABundle)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetCABundle)
+		}
+		// Custom CAs complement the system roots, which may be unavailable,
+		// e.g. on Windows.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, errors.New(errNoCertificates)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if cc := t.ClientCertificate; cc != nil {
+		cert, err := secretKey(ctx, kube, cc.CertSecretRef)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetClientCert)
+		}
+		key, err := secretKey(ctx, kube, cc.KeySecretRef)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetClientKey)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadClientCert)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return cfg, nil
+}
+
+// caBundle reads the CA bundle from the Secret or ConfigMap holding it.
+func caBundle(ctx context.Context, kube client.Client, src *apisv1alpha1.CABundleSource) ([]byte, error) {
+	if src.SecretRef != nil {
+		return secretKey(ctx, kube, *src.SecretRef)
+	}
+
+	ref := src.ConfigMapRef
+	if ref == nil {
+		return nil, errors.New(errNoCABundleSource)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, errors.Wrap(err, errGetConfigMap)
+	}
+	if cm.Data[ref.Key] != "" {
+		return []byte(cm.Data[ref.Key]), nil
+	}
+	if len(cm.BinaryData[ref.Key]) != 0 {
+		return cm.BinaryData[ref.Key], nil
+	}
+	return nil, errors.New(errConfigMapKeyEmpty)
+}
+
+// secretKey reads the selected key of a Secret.
+func secretKey(ctx context.Context, kube client.Client, ref xpv1.SecretKeySelector) ([]byte, error) {
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+	if len(s.Data[ref.Key]) == 0 {
+		return nil, errors.New(errSecretKeyEmpty)
+	}
+	return s.Data[ref.Key], nil
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+const namespace = "crossplane-system"
+
+// emptyPage answers the ping of a client, and every other request, with an
+// empty page.
+var emptyPage = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"values":[],"isLastPage":true}`))
+})
+
+func selector(name, key string) xpv1.SecretKeySelector {
+	return xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: name, Namespace: namespace}, Key: key}
+}
+
+func selectorRef(name, key string) *xpv1.SecretKeySelector {
+	s := selector(name, key)
+	return &s
+}
+
+func secretOf(name string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: data}
+}
+
+func configMapOf(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: data}
+}
+
+// certPEM returns the PEM encoding of the certificate served by srv.
+func certPEM(srv *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+}
+
+// clientCertificate returns the PEM encoded certificate and key of a self
+// signed client certificate.
+func clientCertificate(t *testing.T) (cert []byte, key []byte) {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(...): unexpected error: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "provider-bitbucketserver"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &k.PublicKey, k)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate(...): unexpected error: %s", err)
+	}
+	kder, err := x509.MarshalECPrivateKey(k)
+	if err != nil {
+		t.Fatalf("x509.MarshalECPrivateKey(...): unexpected error: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder})
+}
+
+func newKube(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	return kubefake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+// connect creates a client of the server at baseURL with the options of pc,
+// which pings the server.
+func connect(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig, baseURL string, opts ...bitbucket.Option) error {
+	o, err := ClientOptions(ctx, kube, pc)
+	if err != nil {
+		return err
+	}
+	o = append(o, bitbucket.WithRetryPolicy(bitbucket.RetryPolicy{}))
+	_, err = bitbucket.New(ctx, baseURL, append(o, opts...)...)
+	return err
+}
+
+func TestClientOptionsErrors(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		objs   []client.Object
+		spec   v1alpha1.ProviderConfigSpec
+		want   error
+	}{
+		"InvalidPEM": {
+			reason: "We should report a CA bundle holding no PEM encoded certificates.",
+			objs:   []client.Object{secretOf("ca", map[string][]byte{"ca.crt": []byte("not a certificate")})},
+			spec: v1alpha1.ProviderConfigSpec{TLS: &v1alpha1.TLSConfig{
+				CABundle: &v1alpha1.CABundleSource{SecretRef: selectorRef("ca", "ca.crt")},
+			}},
+			want: errors.New(errNoCertificates),
+		},
+		"CABundleSecretKeyEmpty": {
+			reason: "We should report a CA bundle Secret missing the selected key.",
+			objs:   []client.Object{secretOf("ca", map[string][]byte{})},
+			spec: v1alpha1.ProviderConfigSpec{TLS: &v1alpha1.TLSConfig{
+				CABundle: &v1alpha1.CABundleSource{SecretRef: selectorRef("ca", "ca.crt")},
+			}},
+			want: errors.Wrap(errors.New(errSecretKeyEmpty), errGetCABundle),
+		},
+		"CABundleConfigMapKeyEmpty": {
+			reason: "We should report a CA bundle ConfigMap missing the selected key.",
+			objs:   []client.Object{configMapOf("ca", map[string]string{})},
+			spec: v1alpha1.ProviderConfigSpec{TLS: &v1alpha1.TLSConfig{
+				CABundle: &v1alpha1.CABundleSource{ConfigMapRef: &v1alpha1.ConfigMapKeySelector{Name: "ca", Namespace: namespace, Key: "ca.crt"}},
+			}},
+			want: errors.Wrap(errors.New(errConfigMapKeyEmpty), errGetCABundle),
+		},
+		"NoCABundleSource": {
+			reason: "We should report a CA bundle selecting neither a Secret nor a ConfigMap.",
+			spec:   v1alpha1.ProviderConfigSpec{TLS: &v1alpha1.TLSConfig{CABundle: &v1alpha1.CABundleSource{}}},
+			want:   errors.Wrap(errors.New(errNoCABundleSource), errGetCABundle),
+		},
+		"InvalidKeyPair": {
+			reason: "We should report a client certificate and key that are not PEM encoded.",
+			objs: []client.Object{secretOf("client", map[string][]byte{
+				"tls.crt": []byte("not a certificate"),
+				"tls.key": []byte("not a key"),
+			})},
+			spec: v1alpha1.ProviderConfigSpec{TLS: &v1alpha1.TLSConfig{ClientCertificate: &v1alpha1.ClientCertificate{
+				CertSecretRef: selector("client", "tls.crt"),
+				KeySecretRef:  selector("client", "tls.key"),
+			}}},
+			want: errors.Wrap(errors.New("tls: failed to find any PEM data in certificate input"), errLoadClientCert),
+		},
+		"InvalidProxyURL": {
+			reason: "We should report a proxy URL that cannot be parsed.",
+			spec:   v1alpha1.ProviderConfigSpec{Proxy: &v1alpha1.ProxyConfig{URL: "http://proxy:port"}},
+			want:   errors.Wrap(errors.New(`parse "http://proxy:port": invalid port ":port" after host`), errParseProxyURL),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &v1alpha1.ProviderConfig{Spec: tc.spec}
+			_, err := ClientOptions(context.Background(), newKube(tc.objs...), pc)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nClientOptions(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClientOptionsTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(emptyPage)
+	defer srv.Close()
+
+	cert, key := clientCertificate(t)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(cert)
+	mtls := httptest.NewUnstartedServer(emptyPage)
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	mtls.StartTLS()
+	defer mtls.Close()
+
+	objs := []client.Object{
+		secretOf("ca", map[string][]byte{"ca.crt": certPEM(srv)}),
+		configMapOf("ca", map[string]string{"ca.crt": string(certPEM(srv))}),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca-binary", Namespace: namespace},
+			BinaryData: map[string][]byte{"ca.crt": certPEM(srv)},
+		},
+		secretOf("mtls", map[string][]byte{"ca.crt": certPEM(mtls), "tls.crt": cert, "tls.key": key}),
+	}
+
+	cases := map[string]struct {
+		reason string
+		srv    *httptest.Server
+		tls    *v1alpha1.TLSConfig
+		reach  bool
+	}{
+		"Untrusted": {
+			reason: "We should not reach a server whose certificate is signed by an unknown authority.",
+			srv:    srv,
+		},
+		"CABundleSecret": {
+			reason: "We should trust the certificate authorities of a CA bundle read from a Secret.",
+			srv:    srv,
+			tls:    &v1alpha1.TLSConfig{CABundle: &v1alpha1.CABundleSource{SecretRef: selectorRef("ca", "ca.crt")}},
+			reach:  true,
+		},
+		"CABundleConfigMap": {
+			reason: "We should trust the certificate authorities of a CA bundle read from a ConfigMap.",
+			srv:    srv,
+			tls: &v1alpha1.TLSConfig{CABundle: &v1alpha1.CABundleSource{
+				ConfigMapRef: &v1alpha1.ConfigMapKeySelector{Name: "ca", Namespace: namespace, Key: "ca.crt"},
+			}},
+			reach: true,
+		},
+		"CABundleConfigMapBinaryData": {
+			reason: "We should read a CA bundle from the binary data of a ConfigMap.",
+			srv:    srv,
+			tls: &v1alpha1.TLSConfig{CABundle: &v1alpha1.CABundleSource{
+				ConfigMapRef: &v1alpha1.ConfigMapKeySelector{Name: "ca-binary", Namespace: namespace, Key: "ca.crt"},
+			}},
+			reach: true,
+		},
+		"InsecureSkipVerify": {
+			reason: "We should reach a server whose certificate is not verified when asked to skip the verification.",
+			srv:    srv,
+			tls:    &v1alpha1.TLSConfig{InsecureSkipVerify: true},
+			reach:  true,
+		},
+		"ClientCertificate": {
+			reason: "We should present the client certificate to a server requiring mutual TLS.",
+			srv:    mtls,
+			tls: &v1alpha1.TLSConfig{
+				CABundle: &v1alpha1.CABundleSource{SecretRef: selectorRef("mtls", "ca.crt")},
+				ClientCertificate: &v1alpha1.ClientCertificate{
+					CertSecretRef: selector("mtls", "tls.crt"),
+					KeySecretRef:  selector("mtls", "tls.key"),
+				},
+			},
+			reach: true,
+		},
+		"NoClientCertificate": {
+			reason: "We should not reach a server requiring mutual TLS without a client certificate.",
+			srv:    mtls,
+			tls: &v1alpha1.TLSConfig{
+				CABundle: &v1alpha1.CABundleSource{SecretRef: selectorRef("mtls", "ca.crt")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &v1alpha1.ProviderConfig{Spec: v1alpha1.ProviderConfigSpec{TLS: tc.tls}}
+			err := connect(context.Background(), newKube(objs...), pc, tc.srv.URL)
+			if tc.reach && err != nil {
+				t.Errorf("\n%s\nbitbucket.New(...): unexpected error: %s", tc.reason, err)
+			}
+			if !tc.reach && err == nil {
+				t.Errorf("\n%s\nbitbucket.New(...): want an error, got none", tc.reason)
+			}
+		})
+	}
+}
+
+func TestClientOptionsProxy(t *testing.T) {
+	// The proxy is never used for loopback hosts, so requests go to a host
+	// that does not resolve and only reach a server through the proxy.
+	const baseURL = "http://bitbucket.example.invalid"
+
+	cases := map[string]struct {
+		reason  string
+		noProxy []string
+		want    []string
+	}{
+		"Proxied": {
+			reason: "We should send requests to hosts not excluded from the proxy through it.",
+			want:   []string{"bitbucket.example.invalid"},
+		},
+		"NoProxy": {
+			reason:  "We should send requests to hosts excluded from the proxy directly.",
+			noProxy: []string{".example.invalid"},
+			want:    []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hosts := []string{}
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hosts = append(hosts, r.Host)
+				emptyPage(w, r)
+			}))
+			defer proxy.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			pc := &v1alpha1.ProviderConfig{Spec: v1alpha1.ProviderConfigSpec{
+				Proxy: &v1alpha1.ProxyConfig{URL: proxy.URL, NoProxy: tc.noProxy},
+			}}
+			_ = connect(ctx, newKube(), pc, baseURL)
+			if diff := cmp.Diff(tc.want, hosts); diff != "" {
+				t.Errorf("\n%s\nbitbucket.New(...): -want proxied hosts, +got proxied hosts:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClientOptionsHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		emptyPage(w, r)
+	}))
+	defer srv.Close()
+
+	pc := &v1alpha1.ProviderConfig{Spec: v1alpha1.ProviderConfigSpec{
+		Headers: map[string]string{"X-Team": "platform", "authorization": "Bearer other"},
+	}}
+	if err := connect(context.Background(), newKube(), pc, srv.URL, bitbucket.WithAuth(bitbucket.AuthBasic, "Y3JlZHM=")); err != nil {
+		t.Fatalf("bitbucket.New(...): unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff("platform", got.Get("X-Team")); diff != "" {
+		t.Errorf("We should send the custom headers with every request.\n-want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"Basic Y3JlZHM="}, got.Values("Authorization")); diff != "" {
+		t.Errorf("We should not let a custom header override the credentials of the client.\n-want, +got:\n%s\n", diff)
+	}
+}
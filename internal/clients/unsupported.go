@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+)
+
+// Unsupported returns the external client of managed resources the server
+// does not support in its version, err telling why. Rather than failing, and
+// being retried until the server is upgraded, the resources are reported as
+// unavailable and checked again every poll interval.
+func Unsupported(err error) managed.ExternalClient {
+	return &unsupported{err: err}
+}
+
+type unsupported struct {
+	err error
+}
+
+// Observe reports the resource as up to date, so that it is neither created
+// nor updated. A resource being deleted cannot be observed, so its deletion
+// fails until the server supports it. Reporting it as gone would orphan what
+// the server holds for it, e.g. when it was created before the server was
+// downgraded. A resource without an external name was never created though,
+// so it is reported as gone and its deletion succeeds.
+func (u *unsupported) Observe(_ context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	mg.SetConditions(apisv1alpha1.UnsupportedServerVersion(u.err))
+	if meta.WasDeleted(mg) {
+		if meta.GetExternalName(mg) == "" {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, u.err
+	}
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+func (u *unsupported) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, u.err
+}
+
+func (u *unsupported) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, u.err
+}
+
+func (u *unsupported) Delete(_ context.Context, _ resource.Managed) error {
+	return u.err
+}
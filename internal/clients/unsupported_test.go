@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+func TestUnsupportedObserve(t *testing.T) {
+	errUnsupported := errors.New("project webhooks are unsupported by server version 7.0.0")
+	now := metav1.Now()
+
+	type want struct {
+		o          managed.ExternalObservation
+		err        error
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     *fake.Managed
+		want   want
+	}{
+		"Unsupported": {
+			reason: "We should report a resource the server does not support as up to date, so that it is neither created nor updated.",
+			mg:     &fake.Managed{},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				conditions: []xpv1.Condition{apisv1alpha1.UnsupportedServerVersion(errUnsupported)},
+			},
+		},
+		"Deleted": {
+			reason: "We should fail to observe a resource being deleted, rather than report it as gone and orphan what the server holds for it.",
+			mg: &fake.Managed{ObjectMeta: metav1.ObjectMeta{
+				DeletionTimestamp: &now,
+				Annotations:       map[string]string{meta.AnnotationKeyExternalName: "1"},
+			}},
+			want: want{
+				err:        errUnsupported,
+				conditions: []xpv1.Condition{apisv1alpha1.UnsupportedServerVersion(errUnsupported)},
+			},
+		},
+		"DeletedNeverCreated": {
+			reason: "We should report a resource being deleted that has no external name as gone, as it was never created and nothing would be orphaned.",
+			mg:     &fake.Managed{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}},
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: false},
+				conditions: []xpv1.Condition{apisv1alpha1.UnsupportedServerVersion(errUnsupported)},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Unsupported(errUnsupported).Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nu.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nu.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, tc.mg.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nu.Observe(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnsupportedChanges(t *testing.T) {
+	errUnsupported := errors.New("access tokens are unsupported by server version 5.4.0")
+	u := Unsupported(errUnsupported)
+
+	if _, err := u.Create(context.Background(), &fake.Managed{}); !errors.Is(err, errUnsupported) {
+		t.Errorf("We should not create a resource the server does not support, want error %v, got %v", errUnsupported, err)
+	}
+	if _, err := u.Update(context.Background(), &fake.Managed{}); !errors.Is(err, errUnsupported) {
+		t.Errorf("We should not update a resource the server does not support, want error %v, got %v", errUnsupported, err)
+	}
+	if err := u.Delete(context.Background(), &fake.Managed{}); !errors.Is(err, errUnsupported) {
+		t.Errorf("We should not report a resource the server does not support as deleted, want error %v, got %v", errUnsupported, err)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compare
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSameSet(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a      []string
+		b      []string
+		want   bool
+	}{
+		"Empty": {
+			reason: "A nil and an empty set should be the same.",
+			a:      nil,
+			b:      []string{},
+			want:   true,
+		},
+		"Reordered": {
+			reason: "Sets holding the same elements in a different order should be the same.",
+			a:      []string{"a", "b", "c"},
+			b:      []string{"c", "a", "b"},
+			want:   true,
+		},
+		"DifferentElement": {
+			reason: "Sets holding different elements should not be the same.",
+			a:      []string{"a", "b"},
+			b:      []string{"a", "c"},
+			want:   false,
+		},
+		"DifferentLength": {
+			reason: "Sets of different lengths should not be the same.",
+			a:      []string{"a"},
+			b:      []string{"a", "a"},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := append([]string(nil), tc.a...)
+			got := SameSet(tc.a, tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSameSet(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(a, tc.a, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nSameSet(...): must not reorder its arguments:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesskey
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/sshaccess"
+)
+
+// scope tells the access key controller which access a AccessKey grants.
+var scope = sshaccess.Scope[*v1alpha1.AccessKey]{
+	Kind: v1alpha1.AccessKeyKind,
+	Key: func(cr *v1alpha1.AccessKey) sshaccess.Key {
+		return sshaccess.Key{
+			ProjectKey:         cr.Spec.ForProvider.ProjectKey,
+			RepositorySlug:     cr.Spec.ForProvider.RepositorySlug,
+			PublicKeySecretRef: cr.Spec.ForProvider.PublicKeySecretRef,
+			Label:              cr.Spec.ForProvider.Label,
+			Permission:         cr.Spec.ForProvider.Permission,
+		}
+	},
+	Observe: func(cr *v1alpha1.AccessKey, id int, permission string) {
+		cr.Status.AtProvider = v1alpha1.AccessKeyObservation{ID: id, Permission: permission}
+	},
+}
+
+// Setup adds a controller that reconciles AccessKey managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return sshaccess.Setup(mgr, o, v1alpha1.AccessKeyGroupVersionKind, &v1alpha1.AccessKey{}, scope)
+}
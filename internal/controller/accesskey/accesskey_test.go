@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesskey
+
+import (
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/sshaccess"
+)
+
+// The reconciliation logic is shared by all access key kinds and tested by the
+// sshaccess package, so only what is specific to this kind is tested here.
+
+func TestScope(t *testing.T) {
+	ref := &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Name: "deploy-key", Namespace: "crossplane-system"},
+		Key:             "id_rsa.pub",
+	}
+	cr := &v1alpha1.AccessKey{
+		Spec: v1alpha1.AccessKeySpec{
+			ForProvider: v1alpha1.AccessKeyParameters{
+				ProjectKey:         "PRJ",
+				RepositorySlug:     "my-repo",
+				PublicKeySecretRef: ref,
+				Label:              "deploy",
+				Permission:         "REPO_READ",
+			},
+		},
+	}
+
+	want := sshaccess.Key{
+		ProjectKey:         "PRJ",
+		RepositorySlug:     "my-repo",
+		PublicKeySecretRef: ref,
+		Label:              "deploy",
+		Permission:         "REPO_READ",
+	}
+	if diff := cmp.Diff(want, scope.Key(cr)); diff != "" {
+		t.Errorf("scope.Key(...): -want, +got:\n%s\n", diff)
+	}
+
+	scope.Observe(cr, 9, "REPO_READ")
+	if diff := cmp.Diff(v1alpha1.AccessKeyObservation{ID: 9, Permission: "REPO_READ"}, cr.Status.AtProvider); diff != "" {
+		t.Errorf("scope.Observe(...): -want, +got:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesstoken
+
+import (
+	"context"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/accesstoken/v1alpha1"
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/compare"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+)
+
+const (
+	errNotAccessToken = "managed resource is not an AccessToken custom resource"
+	errTrackPCUsage   = "cannot track ProviderConfig usage"
+
+	errNewClient       = "cannot create new Service"
+	errGetCapabilities = "cannot get capabilities of the server"
+
+	errGetAccessToken    = "cannot get Bitbucket access token"
+	errCreateAccessToken = "cannot create Bitbucket access token"
+	errUpdateAccessToken = "cannot update Bitbucket access token"
+	errDeleteAccessToken = "cannot delete Bitbucket access token"
+)
+
+// Token is the connection detail key the secret value of the token is
+// published under.
+const Token = "token"
+
+// Setup adds a controller that reconciles AccessToken managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.AccessTokenGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.AccessTokenGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AccessToken{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.AccessToken)
+	if !ok {
+		return nil, errors.New(errNotAccessToken)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	caps, err := bc.Capabilities(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCapabilities)
+	}
+	if err := caps.Require(bitbucket.FeatureAccessTokens); err != nil {
+		return clients.Unsupported(err), nil
+	}
+
+	return &external{tokens: bc.AccessTokens, now: time.Now}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external struct {
+	// tokens is used to manage access tokens through the bitbucket api.
+	tokens bitbucket.AccessTokenService
+	// now tells whether a token has expired.
+	now func() time.Time
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.AccessToken)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAccessToken)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	t, err := c.tokens.GetAccessToken(ctx, &bitbucket.GetAccessTokenRequest{
+		TokenScope: scope(cr),
+		ID:         meta.GetExternalName(cr),
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAccessToken)
+	}
+
+	cr.Status.AtProvider = v1alpha1.AccessTokenObservation{
+		ID:          t.ID,
+		CreatedDate: millisToTime(t.CreatedDate),
+		ExpiryDate:  millisToTime(t.ExpiryDate),
+	}
+
+	// An expired token is as good as a deleted one, reporting it as not
+	// existing has it created again. A token being deleted is reported as
+	// existing though, so that Delete revokes what the server still holds.
+	if t.ExpiryDate != 0 && !c.now().Before(time.UnixMilli(t.ExpiryDate)) && !meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr, t),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.AccessToken)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotAccessToken)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	// Clean up the expired token being replaced, if any.
+	if id := meta.GetExternalName(cr); id != "" {
+		err := c.tokens.DeleteAccessToken(ctx, &bitbucket.DeleteAccessTokenRequest{TokenScope: scope(cr), ID: id})
+		if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalCreation{}, errors.Wrap(err, errDeleteAccessToken)
+		}
+	}
+
+	req := &bitbucket.CreateAccessTokenRequest{
+		TokenScope:  scope(cr),
+		Name:        tokenName(cr),
+		Permissions: permissions(cr),
+	}
+	if cr.Spec.ForProvider.ExpiryDays != nil {
+		req.ExpiryDays = *cr.Spec.ForProvider.ExpiryDays
+	}
+
+	t, err := c.tokens.CreateAccessToken(ctx, req)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateAccessToken)
+	}
+
+	meta.SetExternalName(cr, t.ID)
+
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{Token: []byte(t.Token)}}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.AccessToken)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAccessToken)
+	}
+
+	_, err := c.tokens.UpdateAccessToken(ctx, &bitbucket.UpdateAccessTokenRequest{
+		TokenScope:  scope(cr),
+		ID:          meta.GetExternalName(cr),
+		Name:        tokenName(cr),
+		Permissions: permissions(cr),
+	})
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrap(err, errUpdateAccessToken)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.AccessToken)
+	if !ok {
+		return errors.New(errNotAccessToken)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	err := c.tokens.DeleteAccessToken(ctx, &bitbucket.DeleteAccessTokenRequest{
+		TokenScope: scope(cr),
+		ID:         meta.GetExternalName(cr),
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteAccessToken)
+	}
+
+	return nil
+}
+
+// scope returns what the token grants access to.
+func scope(cr *v1alpha1.AccessToken) bitbucket.TokenScope {
+	return bitbucket.TokenScope{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		User:           cr.Spec.ForProvider.User,
+	}
+}
+
+// tokenName returns the configured name of the token, falling back to the
+// name of the managed resource.
+func tokenName(cr *v1alpha1.AccessToken) string {
+	if cr.Spec.ForProvider.Name != "" {
+		return cr.Spec.ForProvider.Name
+	}
+	return cr.GetName()
+}
+
+func permissions(cr *v1alpha1.AccessToken) []string {
+	p := make([]string, len(cr.Spec.ForProvider.Permissions))
+	for i := range cr.Spec.ForProvider.Permissions {
+		p[i] = string(cr.Spec.ForProvider.Permissions[i])
+	}
+	return p
+}
+
+func isUpToDate(cr *v1alpha1.AccessToken, t *bitbucket.AccessToken) bool {
+	return t.Name == tokenName(cr) && compare.SameSet(permissions(cr), t.Permissions)
+}
+
+// millisToTime converts a bitbucket timestamp, which is zero when unset.
+func millisToTime(ms int64) *metav1.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := metav1.NewTime(time.UnixMilli(ms))
+	return &t
+}
@@ -0,0 +1,416 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesstoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/accesstoken/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	projectKey = "PRJ"
+	name       = "ci"
+	secret     = "BBDC-secret"
+
+	now     = time.UnixMilli(1700000000000)
+	created = now.Add(-24 * time.Hour)
+	expiry  = now.Add(24 * time.Hour)
+)
+
+type accessTokenModifier func(*v1alpha1.AccessToken)
+
+func withExternalName(n string) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { meta.SetExternalName(t, n) }
+}
+
+func withPermissions(p ...v1alpha1.TokenPermission) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { t.Spec.ForProvider.Permissions = p }
+}
+
+func withExpiryDays(d int) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { t.Spec.ForProvider.ExpiryDays = &d }
+}
+
+func withConditions(c ...xpv1.Condition) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { t.Status.SetConditions(c...) }
+}
+
+func withDeletionTimestamp(ts time.Time) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { t.SetDeletionTimestamp(&metav1.Time{Time: ts}) }
+}
+
+func withObservation(o v1alpha1.AccessTokenObservation) accessTokenModifier {
+	return func(t *v1alpha1.AccessToken) { t.Status.AtProvider = o }
+}
+
+func accessToken(m ...accessTokenModifier) *v1alpha1.AccessToken {
+	cr := &v1alpha1.AccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.AccessTokenSpec{
+			ForProvider: v1alpha1.AccessTokenParameters{
+				ProjectKey:  projectKey,
+				Permissions: []v1alpha1.TokenPermission{"PROJECT_READ", "REPO_WRITE"},
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func timePtr(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+func TestObserve(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.AccessToken
+		err error
+	}
+
+	observed := func(expiryDate time.Time) *bitbucket.AccessToken {
+		return &bitbucket.AccessToken{
+			ID:          "1234",
+			Name:        name,
+			Permissions: []string{"REPO_WRITE", "PROJECT_READ"},
+			CreatedDate: created.UnixMilli(),
+			ExpiryDate:  expiryDate.UnixMilli(),
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		tokens bitbucket.AccessTokenService
+		args   args
+		want   want
+	}{
+		"NotCreated": {
+			reason: "We should report a token without an ID as not existing.",
+			args:   args{ctx: context.Background(), mg: accessToken()},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: accessToken(),
+			},
+		},
+		"NotFound": {
+			reason: "We should report a deleted token as not existing.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: accessToken(withExternalName("1234")),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"))},
+			want: want{
+				cr:  accessToken(withExternalName("1234")),
+				err: errors.Wrap(errBoom, errGetAccessToken),
+			},
+		},
+		"Expired": {
+			reason: "We should report an expired token as not existing so it is created again.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return observed(now), nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"))},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+				cr: accessToken(
+					withExternalName("1234"),
+					withObservation(v1alpha1.AccessTokenObservation{ID: "1234", CreatedDate: timePtr(created), ExpiryDate: timePtr(now)}),
+				),
+			},
+		},
+		"ExpiredDeleted": {
+			reason: "We should report an expired token being deleted as existing so that it is revoked rather than left behind.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return observed(now), nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"), withDeletionTimestamp(now))},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				cr: accessToken(
+					withExternalName("1234"),
+					withDeletionTimestamp(now),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.AccessTokenObservation{ID: "1234", CreatedDate: timePtr(created), ExpiryDate: timePtr(now)}),
+				),
+			},
+		},
+		"UpToDate": {
+			reason: "We should report a token with the desired name and permissions as up to date.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(_ context.Context, req *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.ProjectKey != projectKey || req.RepositorySlug != "" || req.User != "" || req.ID != "1234" {
+						return nil, bitbucket.ErrNotFound
+					}
+					return observed(expiry), nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"))},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				cr: accessToken(
+					withExternalName("1234"),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.AccessTokenObservation{ID: "1234", CreatedDate: timePtr(created), ExpiryDate: timePtr(expiry)}),
+				),
+			},
+		},
+		"PermissionsChanged": {
+			reason: "We should report a token with other permissions as not up to date.",
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return observed(expiry), nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: accessToken(withExternalName("1234"), withPermissions("PROJECT_ADMIN"))},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				cr: accessToken(
+					withExternalName("1234"),
+					withPermissions("PROJECT_ADMIN"),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.AccessTokenObservation{ID: "1234", CreatedDate: timePtr(created), ExpiryDate: timePtr(expiry)}),
+				),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{tokens: tc.tokens, now: func() time.Time { return now }}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		c   managed.ExternalCreation
+		cr  *v1alpha1.AccessToken
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		tokens bitbucket.AccessTokenService
+		mg     resource.Managed
+		want   want
+	}{
+		"Created": {
+			reason: "We should create the token and publish its secret value.",
+			tokens: &fake.MockAccessTokenService{
+				MockCreateAccessToken: func(_ context.Context, req *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.ProjectKey != projectKey || req.Name != name || req.ExpiryDays != 30 || len(req.Permissions) != 2 {
+						return nil, errBoom
+					}
+					return &bitbucket.AccessToken{ID: "1234", Token: secret}, nil
+				},
+			},
+			mg: accessToken(withExpiryDays(30)),
+			want: want{
+				c:  managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{Token: []byte(secret)}},
+				cr: accessToken(withExpiryDays(30), withExternalName("1234"), withConditions(xpv1.Creating())),
+			},
+		},
+		"Replaced": {
+			reason: "We should delete the expired token before creating its replacement.",
+			tokens: &fake.MockAccessTokenService{
+				MockDeleteAccessToken: func(_ context.Context, req *bitbucket.DeleteAccessTokenRequest) error {
+					if req.ID != "1234" {
+						return errBoom
+					}
+					return bitbucket.ErrNotFound
+				},
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "5678", Token: secret}, nil
+				},
+			},
+			mg: accessToken(withExternalName("1234")),
+			want: want{
+				c:  managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{Token: []byte(secret)}},
+				cr: accessToken(withExternalName("5678"), withConditions(xpv1.Creating())),
+			},
+		},
+		"CreateFailed": {
+			reason: "We should return errors creating the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return nil, errBoom
+				},
+			},
+			mg: accessToken(),
+			want: want{
+				cr:  accessToken(withConditions(xpv1.Creating())),
+				err: errors.Wrap(errBoom, errCreateAccessToken),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{tokens: tc.tokens}
+			got, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tokens bitbucket.AccessTokenService
+		mg     resource.Managed
+		err    error
+	}{
+		"Updated": {
+			reason: "We should update the name and permissions of the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockUpdateAccessToken: func(_ context.Context, req *bitbucket.UpdateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.ID != "1234" || req.Name != name || len(req.Permissions) != 1 || req.Permissions[0] != "PROJECT_ADMIN" {
+						return nil, errBoom
+					}
+					return &bitbucket.AccessToken{}, nil
+				},
+			},
+			mg: accessToken(withExternalName("1234"), withPermissions("PROJECT_ADMIN")),
+		},
+		"UpdateFailed": {
+			reason: "We should return errors updating the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockUpdateAccessToken: func(context.Context, *bitbucket.UpdateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return nil, errBoom
+				},
+			},
+			mg:  accessToken(withExternalName("1234")),
+			err: errors.Wrap(errBoom, errUpdateAccessToken),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{tokens: tc.tokens}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tokens bitbucket.AccessTokenService
+		err    error
+	}{
+		"Deleted": {
+			reason: "We should delete the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockDeleteAccessToken: func(context.Context, *bitbucket.DeleteAccessTokenRequest) error { return nil },
+			},
+		},
+		"AlreadyDeleted": {
+			reason: "We should not return an error when the token is already gone.",
+			tokens: &fake.MockAccessTokenService{
+				MockDeleteAccessToken: func(context.Context, *bitbucket.DeleteAccessTokenRequest) error { return bitbucket.ErrNotFound },
+			},
+		},
+		"DeleteFailed": {
+			reason: "We should return errors deleting the token.",
+			tokens: &fake.MockAccessTokenService{
+				MockDeleteAccessToken: func(context.Context, *bitbucket.DeleteAccessTokenRequest) error { return errBoom },
+			},
+			err: errors.Wrap(errBoom, errDeleteAccessToken),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{tokens: tc.tokens}
+			err := e.Delete(context.Background(), accessToken(withExternalName("1234")))
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -20,8 +20,19 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/accesskey"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/accesstoken"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/branchrestriction"
 	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/config"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/defaultreviewercondition"
 	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/project"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/projectaccesskey"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/projectgrouppermission"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/projectuserpermission"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/repository"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/repositorygrouppermission"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/repositoryuserpermission"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/webhook"
 )
 
 // Setup creates all BitbucketServer controllers with the supplied logger and adds them to
@@ -30,6 +41,17 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
 		project.Setup,
+		projectuserpermission.Setup,
+		projectgrouppermission.Setup,
+		repository.Setup,
+		repositoryuserpermission.Setup,
+		repositorygrouppermission.Setup,
+		branchrestriction.Setup,
+		webhook.Setup,
+		defaultreviewercondition.Setup,
+		accesskey.Setup,
+		projectaccesskey.Setup,
+		accesstoken.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
@@ -0,0 +1,298 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package branchrestriction
+
+import (
+	"context"
+	"strconv"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/branch/v1alpha1"
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/compare"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/refmatcher"
+)
+
+const (
+	errNotBranchRestriction = "managed resource is not a BranchRestriction custom resource"
+	errTrackPCUsage         = "cannot track ProviderConfig usage"
+
+	errNewClient = "cannot create new Service"
+
+	errInvalidID         = "external name is not a valid branch restriction ID"
+	errGetRestriction    = "cannot get branch restriction"
+	errCreateRestriction = "cannot create branch restriction"
+	errDeleteRestriction = "cannot delete branch restriction"
+	errUpdateCR          = "cannot update BranchRestriction custom resource"
+)
+
+// Setup adds a controller that reconciles BranchRestriction managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.BranchRestrictionGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.BranchRestrictionGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.BranchRestriction{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.BranchRestriction)
+	if !ok {
+		return nil, errors.New(errNotBranchRestriction)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{kube: c.kube, restrictions: bc.BranchRestrictions}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external struct {
+	// kube is used to save the ID of replaced restrictions.
+	kube client.Client
+
+	// restrictions is used to manage branch restrictions through the bitbucket api.
+	restrictions bitbucket.BranchRestrictionService
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.BranchRestriction)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotBranchRestriction)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errInvalidID)
+	}
+
+	r, err := c.restrictions.GetBranchRestriction(ctx, &bitbucket.GetBranchRestrictionRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetRestriction)
+	}
+
+	cr.Status.AtProvider.ID = r.ID
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  isUpToDate(cr.Spec.ForProvider, r),
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.BranchRestriction)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotBranchRestriction)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	r, err := c.restrictions.CreateBranchRestriction(ctx, generateCreateRequest(cr.Spec.ForProvider))
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRestriction)
+	}
+
+	meta.SetExternalName(cr, strconv.Itoa(r.ID))
+
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+// Update posts the desired restriction again. Bitbucket updates the existing
+// restriction in place when its type and matcher are unchanged and creates a
+// new one otherwise, in which case the old restriction is removed.
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.BranchRestriction)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotBranchRestriction)
+	}
+
+	r, err := c.restrictions.CreateBranchRestriction(ctx, generateCreateRequest(cr.Spec.ForProvider))
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errCreateRestriction)
+	}
+
+	old := meta.GetExternalName(cr)
+	if old == strconv.Itoa(r.ID) {
+		return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+	}
+
+	// The managed reconciler only saves the status of the managed resource
+	// after an update, so the ID of the new restriction has to be saved here
+	// before the old one is removed. Should saving fail the next update finds
+	// the new restriction in place again.
+	meta.SetExternalName(cr, strconv.Itoa(r.ID))
+	if err := c.kube.Update(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCR)
+	}
+
+	if id, err := strconv.Atoi(old); err == nil {
+		if err := c.delete(ctx, cr, id); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.BranchRestriction)
+	if !ok {
+		return errors.New(errNotBranchRestriction)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return errors.Wrap(err, errInvalidID)
+	}
+
+	return c.delete(ctx, cr, id)
+}
+
+func (c *external) delete(ctx context.Context, cr *v1alpha1.BranchRestriction, id int) error {
+	err := c.restrictions.DeleteBranchRestriction(ctx, &bitbucket.DeleteBranchRestrictionRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteRestriction)
+	}
+
+	return nil
+}
+
+func generateCreateRequest(p v1alpha1.BranchRestrictionParameters) *bitbucket.CreateBranchRestrictionRequest {
+	return &bitbucket.CreateBranchRestrictionRequest{
+		ProjectKey:     p.ProjectKey,
+		RepositorySlug: p.RepositorySlug,
+		Type:           p.Type,
+		Matcher:        refmatcher.Generate(p.Matcher.Type, p.Matcher.ID),
+		Users:          nonNilStrings(p.Users),
+		Groups:         nonNilStrings(p.Groups),
+		AccessKeys:     nonNilInts(p.AccessKeyIDs),
+	}
+}
+
+func isUpToDate(p v1alpha1.BranchRestrictionParameters, r *bitbucket.BranchRestriction) bool {
+	if p.Type != r.Type {
+		return false
+	}
+	if !refmatcher.Matches(p.Matcher.Type, p.Matcher.ID, r.Matcher) {
+		return false
+	}
+
+	users := make([]string, len(r.Users))
+	for i, u := range r.Users {
+		users[i] = u.Name
+	}
+	keys := make([]string, len(r.AccessKeys))
+	for i, k := range r.AccessKeys {
+		keys[i] = strconv.Itoa(k.Key.ID)
+	}
+	wantKeys := make([]string, len(p.AccessKeyIDs))
+	for i, k := range p.AccessKeyIDs {
+		wantKeys[i] = strconv.Itoa(k)
+	}
+
+	return compare.SameSet(p.Users, users) && compare.SameSet(p.Groups, r.Groups) && compare.SameSet(wantKeys, keys)
+}
+
+// nonNilStrings returns an empty slice for nil so that it is sent as [] rather
+// than null, which bitbucket rejects.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nonNilInts is like nonNilStrings for ints.
+func nonNilInts(s []int) []int {
+	if s == nil {
+		return []int{}
+	}
+	return s
+}
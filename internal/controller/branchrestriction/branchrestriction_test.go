@@ -0,0 +1,487 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package branchrestriction
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/branch/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/refmatcher"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	scheme = func() *runtime.Scheme {
+		s := runtime.NewScheme()
+		_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+		return s
+	}()
+
+	projectKey = "PRJ"
+	slug       = "my-repo"
+)
+
+type restrictionModifier func(*v1alpha1.BranchRestriction)
+
+func withExternalName(n string) restrictionModifier {
+	return func(r *v1alpha1.BranchRestriction) { meta.SetExternalName(r, n) }
+}
+
+func withType(t string) restrictionModifier {
+	return func(r *v1alpha1.BranchRestriction) { r.Spec.ForProvider.Type = t }
+}
+
+func withUsers(u ...string) restrictionModifier {
+	return func(r *v1alpha1.BranchRestriction) { r.Spec.ForProvider.Users = u }
+}
+
+func withConditions(c ...xpv1.Condition) restrictionModifier {
+	return func(r *v1alpha1.BranchRestriction) { r.Status.SetConditions(c...) }
+}
+
+func withID(id int) restrictionModifier {
+	return func(r *v1alpha1.BranchRestriction) { r.Status.AtProvider.ID = id }
+}
+
+func restriction(m ...restrictionModifier) *v1alpha1.BranchRestriction {
+	cr := &v1alpha1.BranchRestriction{
+		ObjectMeta: metav1.ObjectMeta{Name: "main-no-deletes"},
+		Spec: v1alpha1.BranchRestrictionSpec{
+			ForProvider: v1alpha1.BranchRestrictionParameters{
+				ProjectKey:     projectKey,
+				RepositorySlug: slug,
+				Type:           bitbucket.RestrictionNoDeletes,
+				Matcher:        v1alpha1.BranchMatcher{Type: bitbucket.MatcherBranch, ID: "main"},
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.BranchRestriction
+		err error
+	}
+
+	observed := &bitbucket.BranchRestriction{
+		ID:   7,
+		Type: bitbucket.RestrictionNoDeletes,
+		Matcher: bitbucket.RefMatcher{
+			ID:        "refs/heads/main",
+			DisplayID: "main",
+			Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch, Name: "Branch"},
+			Active:    true,
+		},
+		Users:  []bitbucket.User{{Name: "bob"}, {Name: "alice"}},
+		Groups: []string{},
+	}
+
+	cases := map[string]struct {
+		reason       string
+		restrictions bitbucket.BranchRestrictionService
+		args         args
+		want         want
+	}{
+		"NotCreated": {
+			reason: "We should report a restriction without an ID as not existing.",
+			args:   args{ctx: context.Background(), mg: restriction()},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: restriction(),
+			},
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			args:   args{ctx: context.Background(), mg: restriction(withExternalName("main"))},
+			want: want{
+				cr:  restriction(withExternalName("main")),
+				err: errors.Wrap(errors.New(`strconv.Atoi: parsing "main": invalid syntax`), errInvalidID),
+			},
+		},
+		"NotFound": {
+			reason: "We should report a deleted restriction as not existing.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockGetBranchRestriction: func(context.Context, *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			},
+			args: args{ctx: context.Background(), mg: restriction(withExternalName("7"))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: restriction(withExternalName("7")),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the restriction.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockGetBranchRestriction: func(context.Context, *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: restriction(withExternalName("7"))},
+			want: want{
+				cr:  restriction(withExternalName("7")),
+				err: errors.Wrap(errBoom, errGetRestriction),
+			},
+		},
+		"UpToDate": {
+			reason: "We should ignore the order of exempted users and the refs/heads/ prefix of branches.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockGetBranchRestriction: func(_ context.Context, req *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 7 {
+						return nil, bitbucket.ErrNotFound
+					}
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: restriction(withExternalName("7"), withUsers("alice", "bob"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: restriction(withExternalName("7"), withUsers("alice", "bob"), withConditions(xpv1.Available()), withID(7)),
+			},
+		},
+		"UsersDrift": {
+			reason: "We should report a restriction exempting other users as not up to date.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockGetBranchRestriction: func(context.Context, *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: restriction(withExternalName("7"), withUsers("alice"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: restriction(withExternalName("7"), withUsers("alice"), withConditions(xpv1.Available()), withID(7)),
+			},
+		},
+		"TypeDrift": {
+			reason: "We should report a restriction of another type as not up to date.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockGetBranchRestriction: func(context.Context, *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: restriction(withExternalName("7"), withUsers("alice", "bob"), withType(bitbucket.RestrictionReadOnly))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: restriction(withExternalName("7"), withUsers("alice", "bob"), withType(bitbucket.RestrictionReadOnly), withConditions(xpv1.Available()), withID(7)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{restrictions: tc.restrictions}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		cr  *v1alpha1.BranchRestriction
+		err error
+	}
+
+	cases := map[string]struct {
+		reason       string
+		restrictions bitbucket.BranchRestrictionService
+		mg           resource.Managed
+		want         want
+	}{
+		"Created": {
+			reason: "We should use the ID of the new restriction as external name.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockCreateBranchRestriction: func(_ context.Context, req *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					if req.Matcher.ID != "refs/heads/main" || req.Matcher.DisplayID != "main" || req.Users == nil {
+						return nil, errBoom
+					}
+					return &bitbucket.BranchRestriction{ID: 7}, nil
+				},
+			},
+			mg:   restriction(),
+			want: want{cr: restriction(withExternalName("7"), withConditions(xpv1.Creating()))},
+		},
+		"CreateFailed": {
+			reason: "We should return errors creating the restriction.",
+			restrictions: &fake.MockBranchRestrictionService{
+				MockCreateBranchRestriction: func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+					return nil, errBoom
+				},
+			},
+			mg: restriction(),
+			want: want{
+				cr:  restriction(withConditions(xpv1.Creating())),
+				err: errors.Wrap(errBoom, errCreateRestriction),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{restrictions: tc.restrictions}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type want struct {
+		cr      *v1alpha1.BranchRestriction
+		deleted int
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		create func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error)
+		mg     resource.Managed
+		want   want
+	}{
+		"UpdatedInPlace": {
+			reason: "We should keep the ID when bitbucket updates the restriction in place.",
+			create: func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+				return &bitbucket.BranchRestriction{ID: 7}, nil
+			},
+			mg:   restriction(withExternalName("7")),
+			want: want{cr: restriction(withExternalName("7"))},
+		},
+		"UpdateFailed": {
+			reason: "We should return errors updating the restriction.",
+			create: func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+				return nil, errBoom
+			},
+			mg: restriction(withExternalName("7")),
+			want: want{
+				cr:  restriction(withExternalName("7")),
+				err: errors.Wrap(errBoom, errCreateRestriction),
+			},
+		},
+		"SaveIDFailed": {
+			reason: "We should not delete the old restriction when the ID of its replacement can not be saved.",
+			create: func(context.Context, *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+				return &bitbucket.BranchRestriction{ID: 8}, nil
+			},
+			mg: restriction(withExternalName("7"), withType(bitbucket.RestrictionReadOnly)),
+			want: want{
+				cr:  restriction(withExternalName("8"), withType(bitbucket.RestrictionReadOnly)),
+				err: errors.Wrap(kerrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource("branchrestrictions").GroupResource(), "main-no-deletes"), errUpdateCR),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := 0
+			e := external{
+				kube: kubefake.NewClientBuilder().WithScheme(scheme).Build(),
+				restrictions: &fake.MockBranchRestrictionService{
+					MockCreateBranchRestriction: tc.create,
+					MockDeleteBranchRestriction: func(_ context.Context, req *bitbucket.DeleteBranchRestrictionRequest) error {
+						deleted = req.ID
+						return nil
+					},
+				},
+			}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.mg); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want deleted, +got deleted:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		delete func(context.Context, *bitbucket.DeleteBranchRestrictionRequest) error
+		mg     resource.Managed
+		want   error
+	}{
+		"Deleted": {
+			reason: "We should delete the restriction with the ID of the external name.",
+			delete: func(_ context.Context, req *bitbucket.DeleteBranchRestrictionRequest) error {
+				if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 7 {
+					return errBoom
+				}
+				return nil
+			},
+			mg: restriction(withExternalName("7")),
+		},
+		"AlreadyDeleted": {
+			reason: "We should not return an error when the restriction is already gone.",
+			delete: func(context.Context, *bitbucket.DeleteBranchRestrictionRequest) error {
+				return bitbucket.ErrNotFound
+			},
+			mg: restriction(withExternalName("7")),
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			mg:     restriction(withExternalName("main")),
+			want:   errors.Wrap(errors.New(`strconv.Atoi: parsing "main": invalid syntax`), errInvalidID),
+		},
+		"DeleteFailed": {
+			reason: "We should return errors deleting the restriction.",
+			delete: func(context.Context, *bitbucket.DeleteBranchRestrictionRequest) error {
+				return errBoom
+			},
+			mg:   restriction(withExternalName("7")),
+			want: errors.Wrap(errBoom, errDeleteRestriction),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{restrictions: &fake.MockBranchRestrictionService{MockDeleteBranchRestriction: tc.delete}}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReplaceThenObserve(t *testing.T) {
+	ctx := context.Background()
+
+	// restrictions holds the restrictions of the repository, keyed by ID.
+	// Like bitbucket it creates a new restriction when the type or matcher
+	// of a posted restriction differ from those of the existing ones.
+	restrictions := map[int]bitbucket.BranchRestriction{
+		7: {ID: 7, Type: bitbucket.RestrictionNoDeletes, Matcher: refmatcher.Generate(bitbucket.MatcherBranch, "main"), Groups: []string{}},
+	}
+	service := &fake.MockBranchRestrictionService{
+		MockGetBranchRestriction: func(_ context.Context, req *bitbucket.GetBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+			r, ok := restrictions[req.ID]
+			if !ok {
+				return nil, bitbucket.ErrNotFound
+			}
+			return &r, nil
+		},
+		MockCreateBranchRestriction: func(_ context.Context, req *bitbucket.CreateBranchRestrictionRequest) (*bitbucket.BranchRestriction, error) {
+			id := len(restrictions) + 7
+			for _, r := range restrictions {
+				if r.Type == req.Type && r.Matcher.ID == req.Matcher.ID {
+					id = r.ID
+				}
+			}
+			r := bitbucket.BranchRestriction{ID: id, Type: req.Type, Matcher: req.Matcher, Groups: req.Groups}
+			restrictions[id] = r
+			return &r, nil
+		},
+		MockDeleteBranchRestriction: func(_ context.Context, req *bitbucket.DeleteBranchRestrictionRequest) error {
+			delete(restrictions, req.ID)
+			return nil
+		},
+	}
+
+	cr := restriction(withExternalName("7"), withType(bitbucket.RestrictionReadOnly))
+	kube := kubefake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build()
+	e := external{kube: kube, restrictions: service}
+
+	o, err := e.Observe(ctx, cr)
+	if err != nil || !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want existing restriction that is not up to date, got %+v, %v", o, err)
+	}
+	if _, err := e.Update(ctx, cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %s", err)
+	}
+
+	// The managed reconciler observes the restriction as it was saved.
+	saved := &v1alpha1.BranchRestriction{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: cr.GetName()}, saved); err != nil {
+		t.Fatal(err)
+	}
+	o, err = e.Observe(ctx, saved)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %s", err)
+	}
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: managed.ConnectionDetails{}}
+	if diff := cmp.Diff(want, o); diff != "" {
+		t.Errorf("We should observe the replacement of the restriction.\ne.Observe(...): -want, +got:\n%s\n", diff)
+	}
+	if _, ok := restrictions[7]; ok {
+		t.Errorf("We should delete the replaced restriction.")
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlevent "sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
@@ -27,10 +32,18 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
 )
 
+// evictClient drops the cached client of a ProviderConfig once it is deleted.
+var evictClient = handler.Funcs{
+	DeleteFunc: func(e ctrlevent.DeleteEvent, _ workqueue.RateLimitingInterface) {
+		clients.EvictClient(e.Object.GetUID())
+	},
+}
+
 // Setup adds a controller that reconciles ProviderConfigs by accounting for
-// their current usage.
+// their current usage, checking their server and rotating their access token.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
 	name := providerconfig.ControllerName(v1alpha1.ProviderConfigGroupKind)
 
@@ -39,14 +52,29 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		UsageList: v1alpha1.ProviderConfigUsageListGroupVersionKind,
 	}
 
-	r := providerconfig.NewReconciler(mgr, of,
-		providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
-		providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+	r := &healthReconciler{
+		usage: providerconfig.NewReconciler(mgr, of,
+			providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
+			providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))),
+		rotation: &rotator{
+			kube:        mgr.GetClient(),
+			log:         o.Logger.WithValues("controller", name),
+			record:      event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			newClientFn: clients.NewClient,
+			now:         time.Now,
+		},
+		kube:         mgr.GetClient(),
+		reader:       mgr.GetAPIReader(),
+		log:          o.Logger.WithValues("controller", name),
+		newClientFn:  clients.NewClient,
+		pollInterval: o.PollInterval,
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
 		For(&v1alpha1.ProviderConfig{}).
 		Watches(&source.Kind{Type: &v1alpha1.ProviderConfigUsage{}}, &resource.EnqueueRequestForProviderConfig{}).
+		Watches(&source.Kind{Type: &v1alpha1.ProviderConfig{}}, evictClient).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
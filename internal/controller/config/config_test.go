@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrlevent "sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+)
+
+func TestEvictClient(t *testing.T) {
+	pings := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		pings++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"values":[],"isLastPage":true}`))
+	}))
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	pc := providerConfig(func(pc *v1alpha1.ProviderConfig) {
+		pc.SetUID(types.UID(t.Name()))
+		pc.Spec.BaseURL = srv.URL
+	})
+	kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(pc, secret(oldCreds)).Build()
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: pc.GetName()}}}
+	defer clients.EvictClient(pc.GetUID())
+
+	if _, err := clients.GetClient(context.Background(), kube, mg); err != nil {
+		t.Fatalf("clients.GetClient(...): unexpected error: %s", err)
+	}
+	evictClient.Delete(ctrlevent.DeleteEvent{Object: pc}, nil)
+	if _, err := clients.GetClient(context.Background(), kube, mg); err != nil {
+		t.Fatalf("clients.GetClient(...): unexpected error: %s", err)
+	}
+
+	if pings != 2 {
+		t.Errorf("We should build another client once the ProviderConfig of the cached one was deleted, want 2 pings, got %d", pings)
+	}
+}
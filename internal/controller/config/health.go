@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+)
+
+const (
+	errGetCreds         = "cannot get credentials"
+	errGetServerInfo    = "cannot get bitbucket application properties"
+	errNotAuthenticated = "bitbucket served the request anonymously, the credentials were not used to authenticate"
+)
+
+// A healthReconciler accounts for the usages of a ProviderConfig, then checks
+// its credentials, authenticates against its server, rotates its access token
+// and records the outcome in its status. All of this happens in one controller
+// so that they do not race to update the status of the ProviderConfig.
+type healthReconciler struct {
+	// usage accounts for the usages of the ProviderConfig.
+	usage reconcile.Reconciler
+	// rotation rotates the access token of the ProviderConfig.
+	rotation *rotator
+
+	kube client.Client
+	// reader reads ProviderConfigs from the API server rather than the
+	// cache, which may not reflect the status usage just wrote yet.
+	reader      client.Reader
+	log         logging.Logger
+	newClientFn func(ctx context.Context, baseURL string, creds clients.Credentials, opts ...bitbucket.Option) (*bitbucket.Client, error)
+
+	// pollInterval is how often the server is checked, as it may become
+	// unavailable or stop accepting the credentials at any time.
+	pollInterval time.Duration
+}
+
+// Reconcile accounts for the usages of a ProviderConfig, checks its server and
+// rotates its access token.
+func (r *healthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if res, err := r.usage.Reconcile(ctx, req); err != nil || res.RequeueAfter > 0 {
+		// Usage is accounted for again before the server is checked.
+		return res, err
+	}
+
+	log := r.log.WithValues("request", req)
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.reader.Get(ctx, req.NamespacedName, pc); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+	if meta.WasDeleted(pc) {
+		return reconcile.Result{}, nil
+	}
+
+	if p, err := r.check(ctx, pc); err != nil {
+		log.Debug("ProviderConfig is unhealthy", "error", err)
+		pc.Status.SetConditions(v1alpha1.Unhealthy(err))
+	} else {
+		pc.Status.Server = &v1alpha1.ServerStatus{
+			Version:     p.Version,
+			BuildNumber: p.BuildNumber,
+			Username:    p.Username,
+		}
+		pc.Status.SetConditions(v1alpha1.Healthy())
+	}
+
+	// The token is rotated after the server was checked, as the check reads
+	// the credentials from the cache, which may not hold the replacement yet.
+	requeue := r.pollInterval
+	if next := r.rotation.reconcile(ctx, pc); next > 0 && next < requeue {
+		requeue = next
+	}
+	return reconcile.Result{RequeueAfter: requeue}, errors.Wrap(r.kube.Status().Update(ctx, pc), errUpdateStatus)
+}
+
+// check authenticates against the server of the ProviderConfig and returns
+// the properties of the server. It records whether the credentials of the
+// ProviderConfig are usable in its conditions.
+func (r *healthReconciler) check(ctx context.Context, pc *v1alpha1.ProviderConfig) (*bitbucket.ApplicationProperties, error) {
+	cd := pc.Spec.Credentials
+	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, r.kube, cd.CommonCredentialSelectors)
+	if err != nil {
+		err = errors.Wrap(err, errGetCreds)
+		pc.Status.SetConditions(v1alpha1.CredentialsUnavailable(err))
+		return nil, err
+	}
+
+	creds, err := clients.ParseCredentials(pc.Spec.AuthType, data)
+	if err != nil {
+		pc.Status.SetConditions(v1alpha1.CredentialsMalformed(err))
+		return nil, errors.Wrap(err, errParseCreds)
+	}
+	pc.Status.SetConditions(v1alpha1.CredentialsValid())
+
+	opts, err := clients.ClientOptions(ctx, r.kube, pc)
+	if err != nil {
+		return nil, errors.Wrap(err, errClientOptions)
+	}
+
+	// Reading the application properties checks the server, there is no
+	// need to ping it when creating the client.
+	bc, err := r.newClientFn(ctx, pc.Spec.BaseURL, creds, append(opts, bitbucket.WithoutPing())...)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	p, err := bc.Application.GetApplicationProperties(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetServerInfo)
+	}
+	if p.Username == "" {
+		return nil, errors.New(errNotAuthenticated)
+	}
+	return p, nil
+}
@@ -0,0 +1,410 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+func TestHealthReconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	properties := &bitbucket.ApplicationProperties{Version: "8.5.0", BuildNumber: "8005000", Username: "admin"}
+	server := &v1alpha1.ServerStatus{Version: "8.5.0", BuildNumber: "8005000", Username: "admin"}
+
+	errSecretMissing := errors.Wrap(errors.Wrap(errors.New(`secrets "bitbucket-creds" not found`), "cannot get credentials secret"), errGetCreds)
+
+	withServerStatus := func(s *v1alpha1.ServerStatus) providerConfigModifier {
+		return func(pc *v1alpha1.ProviderConfig) { pc.Status.Server = s }
+	}
+
+	type want struct {
+		result     reconcile.Result
+		err        error
+		server     *v1alpha1.ServerStatus
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason      string
+		pc          *v1alpha1.ProviderConfig
+		secret      *corev1.Secret
+		newClientFn func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error)
+		app         *fake.MockApplicationService
+		// usage is the result of accounting for usages, which succeeds
+		// without requeueing by default
+		usage reconcile.Func
+		want  want
+	}{
+		"Healthy": {
+			reason: "We should report the server the provider authenticated against.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			app: &fake.MockApplicationService{
+				MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+					return properties, nil
+				},
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				server:     server,
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Healthy()},
+			},
+		},
+		"SecretMissing": {
+			reason: "We should report a ProviderConfig whose credentials cannot be read as unhealthy.",
+			pc:     providerConfig(withRotation(nil)),
+			want: want{
+				result: reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{
+					v1alpha1.CredentialsUnavailable(errSecretMissing),
+					v1alpha1.Unhealthy(errSecretMissing),
+				},
+			},
+		},
+		"Unreachable": {
+			reason: "We should report a ProviderConfig whose server cannot be reached as unhealthy.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			newClientFn: func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error) {
+				return nil, errBoom
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Unhealthy(errors.Wrap(errBoom, errNewClient))},
+			},
+		},
+		"Rejected": {
+			reason: "We should report a ProviderConfig whose credentials the server rejects as unhealthy, keeping what we last knew about the server.",
+			pc:     providerConfig(withRotation(nil), withServerStatus(server)),
+			secret: secret(oldCreds),
+			app: &fake.MockApplicationService{
+				MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+					return nil, errBoom
+				},
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				server:     server,
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Unhealthy(errors.Wrap(errBoom, errGetServerInfo))},
+			},
+		},
+		"UsageFailed": {
+			reason: "We should not check the server of a ProviderConfig whose usage could not be accounted for.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			usage: func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, errBoom
+			},
+			want: want{err: errBoom},
+		},
+		"UsageRequeued": {
+			reason: "We should not check the server of a ProviderConfig whose usage is accounted for again shortly.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			usage: func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{RequeueAfter: shortWait}, nil
+			},
+			want: want{result: reconcile.Result{RequeueAfter: shortWait}},
+		},
+		"Deleted": {
+			reason: "We should not check the server of a ProviderConfig that is being deleted.",
+			pc: providerConfig(withRotation(nil), func(pc *v1alpha1.ProviderConfig) {
+				now := metav1.Now()
+				pc.SetDeletionTimestamp(&now)
+				pc.SetFinalizers([]string{"in-use.crossplane.io"})
+			}),
+			secret: secret(oldCreds),
+		},
+		"Anonymous": {
+			reason: "We should report a ProviderConfig whose credentials the server did not authenticate as unhealthy.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			app: &fake.MockApplicationService{
+				MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+					return &bitbucket.ApplicationProperties{Version: "8.5.0", BuildNumber: "8005000"}, nil
+				},
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Unhealthy(errors.New(errNotAuthenticated))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs := []client.Object{tc.pc}
+			if tc.secret != nil {
+				objs = append(objs, tc.secret)
+			}
+			kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+			newClientFn := tc.newClientFn
+			if newClientFn == nil {
+				newClientFn = func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error) {
+					return &bitbucket.Client{Application: tc.app}, nil
+				}
+			}
+
+			usage := tc.usage
+			if usage == nil {
+				usage = func(context.Context, reconcile.Request) (reconcile.Result, error) { return reconcile.Result{}, nil }
+			}
+
+			r := &healthReconciler{usage: usage, rotation: &rotator{}, kube: kube, reader: kube, log: logging.NewNopLogger(), newClientFn: newClientFn, pollInterval: time.Minute}
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.pc.GetName()}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			pc := &v1alpha1.ProviderConfig{}
+			_ = kube.Get(context.Background(), types.NamespacedName{Name: tc.pc.GetName()}, pc)
+			if diff := cmp.Diff(tc.want.server, pc.Status.Server); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want server, +got server:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, pc.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHealthCredentials(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	withData := func(data string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bitbucket-creds", Namespace: "crossplane-system"},
+			Data:       map[string][]byte{"credentials": []byte(data)},
+		}
+	}
+	malformed := func(err error) []xpv1.Condition {
+		return []xpv1.Condition{v1alpha1.CredentialsMalformed(err), v1alpha1.Unhealthy(errors.Wrap(err, errParseCreds))}
+	}
+	valid := []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Healthy()}
+
+	cases := map[string]struct {
+		reason string
+		pc     *v1alpha1.ProviderConfig
+		secret *corev1.Secret
+		want   []xpv1.Condition
+	}{
+		"LegacyBasic": {
+			reason: "We should accept the base64 encoding of username:password.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: secret(oldCreds),
+			want:   valid,
+		},
+		"LegacyBearer": {
+			reason: "We should accept a bare token for bearer authentication.",
+			pc:     providerConfig(withRotation(nil), withAuthType(v1alpha1.AuthTypeBearer)),
+			secret: withData("BBDC-token\n"),
+			want:   valid,
+		},
+		"JSONPassword": {
+			reason: "We should accept JSON holding a username and password.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData(`{"username": "admin", "password": "secret"}`),
+			want:   valid,
+		},
+		"JSONToken": {
+			reason: "We should accept JSON holding a token.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData(`{"token": "BBDC-token"}`),
+			want:   valid,
+		},
+		"JSONPasswordAndToken": {
+			reason: "We should reject JSON holding both a password and a token.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData(`{"username": "admin", "password": "secret", "token": "BBDC-token"}`),
+			want:   malformed(errors.New("credentials must not hold both a password and a token")),
+		},
+		"JSONNoPassword": {
+			reason: "We should reject JSON holding a username without password.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData(`{"username": "admin"}`),
+			want:   malformed(errors.New("credentials must hold either a token, or a username and a password")),
+		},
+		"JSONUnknownField": {
+			reason: "We should reject JSON with fields we do not know, which are most likely typos.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData(`{"user": "admin", "password": "secret"}`),
+			want:   malformed(errors.New(`cannot decode JSON credentials: json: unknown field "user"`)),
+		},
+		"LegacyNotBase64": {
+			reason: "We should reject basic credentials that are not base64 encoded.",
+			pc:     providerConfig(withRotation(nil)),
+			secret: withData("admin:secret"),
+			want:   malformed(errors.New("cannot decode base64 credentials: illegal base64 data at input byte 5")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(tc.pc, tc.secret).Build()
+			app := &fake.MockApplicationService{
+				MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+					return &bitbucket.ApplicationProperties{Version: "8.5.0", BuildNumber: "8005000", Username: "admin"}, nil
+				},
+			}
+
+			r := &healthReconciler{
+				usage:    reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) { return reconcile.Result{}, nil }),
+				rotation: &rotator{},
+				kube:     kube,
+				reader:   kube,
+				log:      logging.NewNopLogger(),
+				newClientFn: func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error) {
+					return &bitbucket.Client{Application: app}, nil
+				},
+				pollInterval: time.Minute,
+			}
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.pc.GetName()}}); err != nil {
+				t.Errorf("\n%s\nr.Reconcile(...): unexpected error: %s\n", tc.reason, err)
+			}
+
+			pc := &v1alpha1.ProviderConfig{}
+			_ = kube.Get(context.Background(), types.NamespacedName{Name: tc.pc.GetName()}, pc)
+			if diff := cmp.Diff(tc.want, pc.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHealthRotation(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	app := &fake.MockApplicationService{
+		MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+			return &bitbucket.ApplicationProperties{Version: "8.5.0", BuildNumber: "8005000", Username: "admin"}, nil
+		},
+	}
+	tokenExpiring := func(expiry time.Time) func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+		return func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+			return &bitbucket.AccessToken{ID: "1", ExpiryDate: expiry.UnixMilli()}, nil
+		}
+	}
+
+	type want struct {
+		result     reconcile.Result
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason   string
+		getToken func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error)
+		want     want
+	}{
+		"DueAfterPoll": {
+			reason:   "We should check the server again after the poll interval when the token is due later.",
+			getToken: tokenExpiring(now.Add(30 * 24 * time.Hour)),
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Healthy(), v1alpha1.RotationSucceeded()},
+			},
+		},
+		"DueBeforePoll": {
+			reason:   "We should reconcile again once the token is due when that is before the poll interval.",
+			getToken: tokenExpiring(now.Add(defaultRotateBefore + 10*time.Second)),
+			want: want{
+				result:     reconcile.Result{RequeueAfter: 10 * time.Second},
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Healthy(), v1alpha1.RotationSucceeded()},
+			},
+		},
+		"RotationFailed": {
+			reason: "We should record a failed rotation together with the health of the ProviderConfig, and try again shortly.",
+			getToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+				return nil, errBoom
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: shortWait},
+				conditions: []xpv1.Condition{v1alpha1.CredentialsValid(), v1alpha1.Healthy(), v1alpha1.RotationFailed(errors.Wrap(errBoom, errGetToken))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"}))
+			kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(pc, secret(oldCreds)).Build()
+
+			newClientFn := func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error) {
+				return &bitbucket.Client{Application: app, AccessTokens: &fake.MockAccessTokenService{MockGetAccessToken: tc.getToken}}, nil
+			}
+			r := &healthReconciler{
+				usage: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) { return reconcile.Result{}, nil }),
+				rotation: &rotator{
+					kube:        kube,
+					log:         logging.NewNopLogger(),
+					record:      event.NewNopRecorder(),
+					newClientFn: newClientFn,
+					now:         func() time.Time { return now },
+				},
+				kube:         kube,
+				reader:       kube,
+				log:          logging.NewNopLogger(),
+				newClientFn:  newClientFn,
+				pollInterval: time.Minute,
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: pc.GetName()}})
+			if err != nil {
+				t.Errorf("\n%s\nr.Reconcile(...): unexpected error: %s\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			_ = kube.Get(context.Background(), types.NamespacedName{Name: pc.GetName()}, pc)
+			if diff := cmp.Diff(tc.want.conditions, pc.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
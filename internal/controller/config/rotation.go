@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+)
+
+const (
+	reconcileTimeout    = 1 * time.Minute
+	shortWait           = 30 * time.Second
+	defaultRotateBefore = 7 * 24 * time.Hour
+
+	errGetPC           = "cannot get ProviderConfig"
+	errUpdateStatus    = "cannot update ProviderConfig status"
+	errNotSecretSource = "token rotation requires credentials from a Secret"
+	errNoUsername      = "token rotation requires credentials naming the user the token belongs to"
+	errRotateBefore    = "token rotation requires rotateBefore to be shorter than expiryDays"
+	errGetSecret       = "cannot get credentials Secret"
+	errParseCreds      = "cannot parse credentials"
+	errClientOptions   = "cannot configure bitbucket client"
+	errNewClient       = "cannot create bitbucket client"
+	errGetToken        = "cannot get current access token"
+	errCreateToken     = "cannot create replacement access token"
+	errWriteSecret     = "cannot write replacement access token to credentials Secret"
+	errVerifyToken     = "cannot authenticate with replacement access token"
+	errRevokeToken     = "cannot revoke replaced access token"
+	errForgetToken     = "cannot clear revoked access token from credentials Secret"
+
+	reasonRotate event.Reason = "RotateAccessToken"
+)
+
+const (
+	// AnnotationTokenID is set on a credentials Secret to the ID of the
+	// access token it holds. It is written together with the token, so the
+	// ID survives failing to update the ProviderConfig status.
+	AnnotationTokenID = "bitbucketserver.crossplane.io/access-token-id"
+
+	// AnnotationRevokeTokenID is set on a credentials Secret to the ID of an
+	// access token it no longer holds that is yet to be revoked.
+	AnnotationRevokeTokenID = "bitbucketserver.crossplane.io/revoke-access-token-id"
+)
+
+// A rotator replaces the access token a ProviderConfig authenticates with
+// before the token expires. It records the outcome in the status of the
+// ProviderConfig, but leaves updating the status to the health reconciler.
+type rotator struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+
+	// newClientFn creates a client authenticating with the given credentials,
+	// failing when they are not accepted.
+	newClientFn func(ctx context.Context, baseURL string, creds clients.Credentials, opts ...bitbucket.Option) (*bitbucket.Client, error)
+	now         func() time.Time
+}
+
+// reconcile rotates the access token of ProviderConfig pc once it is due. It
+// returns how long until the token is to be checked again, which is zero when
+// pc did not opt into rotation or its token does not expire.
+func (r *rotator) reconcile(ctx context.Context, pc *v1alpha1.ProviderConfig) time.Duration {
+	if pc.Spec.Rotation == nil || !pc.Spec.Rotation.Enabled {
+		return 0
+	}
+
+	next, err := r.rotate(ctx, pc)
+	if err != nil {
+		r.log.Debug("Cannot rotate access token", "error", err, "providerConfig", pc.GetName())
+		r.record.Event(pc, event.Warning(reasonRotate, err))
+		pc.Status.SetConditions(v1alpha1.RotationFailed(err))
+		return shortWait
+	}
+
+	pc.Status.SetConditions(v1alpha1.RotationSucceeded())
+	return next
+}
+
+// rotate replaces the access token held by the credentials Secret when it is
+// due, and returns how long until the token in place is due. The ID of the
+// replacement is written to the Secret together with the token, and the
+// replacement is verified before the replaced token is revoked.
+func (r *rotator) rotate(ctx context.Context, pc *v1alpha1.ProviderConfig) (time.Duration, error) {
+	// Replacement tokens would be due as soon as they are created, and be
+	// replaced over and over again.
+	if days := pc.Spec.Rotation.ExpiryDays; days > 0 && rotateBefore(pc) >= time.Duration(days)*24*time.Hour {
+		return 0, errors.New(errRotateBefore)
+	}
+
+	cd := pc.Spec.Credentials
+	if cd.Source != xpv1.CredentialsSourceSecret || cd.SecretRef == nil {
+		return 0, errors.New(errNotSecretSource)
+	}
+
+	s := &corev1.Secret{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Namespace: cd.SecretRef.Namespace, Name: cd.SecretRef.Name}, s); err != nil {
+		return 0, errors.Wrap(err, errGetSecret)
+	}
+
+	creds, err := clients.ParseCredentials(pc.Spec.AuthType, s.Data[cd.SecretRef.Key])
+	if err != nil {
+		return 0, errors.Wrap(err, errParseCreds)
+	}
+	if creds.Username == "" {
+		return 0, errors.New(errNoUsername)
+	}
+
+	opts, err := clients.ClientOptions(ctx, r.kube, pc)
+	if err != nil {
+		return 0, errors.Wrap(err, errClientOptions)
+	}
+
+	bc, err := r.newClientFn(ctx, pc.Spec.BaseURL, creds, opts...)
+	if err != nil {
+		return 0, errors.Wrap(err, errNewClient)
+	}
+
+	scope := bitbucket.TokenScope{User: creds.Username}
+
+	// Finish cleaning up after a rotation that was interrupted.
+	if err := r.revokeLeftover(ctx, pc, s, bc, scope); err != nil {
+		return 0, err
+	}
+
+	current := currentTokenID(pc, s)
+	if current != "" {
+		t, err := bc.AccessTokens.GetAccessToken(ctx, &bitbucket.GetAccessTokenRequest{TokenScope: scope, ID: current})
+		switch {
+		case errors.Is(err, bitbucket.ErrNotFound):
+			// The token was revoked behind our back, so the Secret holds a
+			// token we know nothing about.
+			current = ""
+		case err != nil:
+			return 0, errors.Wrap(err, errGetToken)
+		case !r.due(pc, t):
+			return r.observe(pc, t), nil
+		}
+	}
+
+	rot := pc.Spec.Rotation
+	t, err := bc.AccessTokens.CreateAccessToken(ctx, &bitbucket.CreateAccessTokenRequest{
+		TokenScope:  scope,
+		Name:        fmt.Sprintf("%s-%s", pc.GetName(), r.now().UTC().Format("20060102150405")),
+		Permissions: rot.Permissions,
+		ExpiryDays:  rot.ExpiryDays,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, errCreateToken)
+	}
+
+	replacement := creds
+	if creds.Token != "" {
+		replacement.Token = t.Token
+	} else {
+		replacement.Password = t.Token
+	}
+
+	previous := s.DeepCopy()
+	s.Data[cd.SecretRef.Key] = replacement.Encode()
+	meta.AddAnnotations(s, map[string]string{AnnotationTokenID: t.ID})
+	meta.RemoveAnnotations(s, AnnotationRevokeTokenID)
+	if current != "" {
+		meta.AddAnnotations(s, map[string]string{AnnotationRevokeTokenID: current})
+	}
+	if err := r.kube.Update(ctx, s); err != nil {
+		_ = bc.AccessTokens.DeleteAccessToken(ctx, &bitbucket.DeleteAccessTokenRequest{TokenScope: scope, ID: t.ID})
+		return 0, errors.Wrap(err, errWriteSecret)
+	}
+
+	nc, err := r.newClientFn(ctx, pc.Spec.BaseURL, replacement, opts...)
+	if err != nil {
+		// Put the token that still works back in place, and the replacement
+		// up for revocation.
+		previous.SetResourceVersion(s.GetResourceVersion())
+		meta.AddAnnotations(previous, map[string]string{AnnotationRevokeTokenID: t.ID})
+		if r.kube.Update(ctx, previous) == nil {
+			_ = r.revokeLeftover(ctx, pc, previous, bc, scope)
+		}
+		return 0, errors.Wrap(err, errVerifyToken)
+	}
+
+	// The replacement is in place, failing to clean up the replaced token
+	// must not fail the rotation.
+	if err := r.revokeLeftover(ctx, pc, s, nc, scope); err != nil {
+		r.record.Event(pc, event.Warning(reasonRotate, err))
+	}
+
+	now := metav1.NewTime(r.now())
+	if pc.Status.Rotation == nil {
+		pc.Status.Rotation = &v1alpha1.TokenRotationStatus{}
+	}
+	pc.Status.Rotation.LastRotationTime = &now
+	r.record.Event(pc, event.Normal(reasonRotate, "Rotated access token", "tokenID", t.ID))
+
+	return r.observe(pc, t), nil
+}
+
+// due reports whether token t has to be replaced.
+func (r *rotator) due(pc *v1alpha1.ProviderConfig, t *bitbucket.AccessToken) bool {
+	if t.ExpiryDate == 0 {
+		return false
+	}
+	return !r.now().Before(time.UnixMilli(t.ExpiryDate).Add(-rotateBefore(pc)))
+}
+
+// observe records token t as the one in place, and returns how long until it
+// is due. It returns zero for tokens that do not expire.
+func (r *rotator) observe(pc *v1alpha1.ProviderConfig, t *bitbucket.AccessToken) time.Duration {
+	if pc.Status.Rotation == nil {
+		pc.Status.Rotation = &v1alpha1.TokenRotationStatus{}
+	}
+	st := pc.Status.Rotation
+	st.TokenID = t.ID
+	st.ExpiryDate, st.NextRotationTime = nil, nil
+
+	if t.ExpiryDate == 0 {
+		return 0
+	}
+
+	expiry := time.UnixMilli(t.ExpiryDate)
+	next := expiry.Add(-rotateBefore(pc))
+	st.ExpiryDate = &metav1.Time{Time: expiry}
+	st.NextRotationTime = &metav1.Time{Time: next}
+	return next.Sub(r.now())
+}
+
+// revokeLeftover revokes the token the credentials Secret s records as yet to
+// be revoked, and clears the record once the token is gone. Failing to revoke
+// the token is reported but keeps the record, so revoking it is tried again.
+func (r *rotator) revokeLeftover(ctx context.Context, pc *v1alpha1.ProviderConfig, s *corev1.Secret, bc *bitbucket.Client, scope bitbucket.TokenScope) error {
+	id := s.GetAnnotations()[AnnotationRevokeTokenID]
+	if id == "" {
+		return nil
+	}
+	err := bc.AccessTokens.DeleteAccessToken(ctx, &bitbucket.DeleteAccessTokenRequest{TokenScope: scope, ID: id})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		r.record.Event(pc, event.Warning(reasonRotate, errors.Wrap(err, errRevokeToken)))
+		return nil
+	}
+	meta.RemoveAnnotations(s, AnnotationRevokeTokenID)
+	return errors.Wrap(r.kube.Update(ctx, s), errForgetToken)
+}
+
+// currentTokenID returns the ID of the token the credentials Secret s holds,
+// which is the one it records as last rotated in, or the one configured when
+// rotation was enabled.
+func currentTokenID(pc *v1alpha1.ProviderConfig, s *corev1.Secret) string {
+	if id := s.GetAnnotations()[AnnotationTokenID]; id != "" {
+		return id
+	}
+	if pc.Status.Rotation != nil && pc.Status.Rotation.TokenID != "" {
+		return pc.Status.Rotation.TokenID
+	}
+	return pc.Spec.Rotation.TokenID
+}
+
+func rotateBefore(pc *v1alpha1.ProviderConfig) time.Duration {
+	if pc.Spec.Rotation.RotateBefore == nil {
+		return defaultRotateBefore
+	}
+	return pc.Spec.Rotation.RotateBefore.Duration
+}
@@ -0,0 +1,509 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	now = time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	oldCreds = clients.Credentials{Username: "admin", Password: "old-token"}
+	newCreds = clients.Credentials{Username: "admin", Password: "new-token"}
+)
+
+type providerConfigModifier func(*v1alpha1.ProviderConfig)
+
+func withRotation(r *v1alpha1.TokenRotation) providerConfigModifier {
+	return func(pc *v1alpha1.ProviderConfig) { pc.Spec.Rotation = r }
+}
+
+func withRotationStatus(s *v1alpha1.TokenRotationStatus) providerConfigModifier {
+	return func(pc *v1alpha1.ProviderConfig) { pc.Status.Rotation = s }
+}
+
+func withAuthType(a v1alpha1.AuthType) providerConfigModifier {
+	return func(pc *v1alpha1.ProviderConfig) { pc.Spec.AuthType = a }
+}
+
+func withSource(s xpv1.CredentialsSource) providerConfigModifier {
+	return func(pc *v1alpha1.ProviderConfig) { pc.Spec.Credentials.Source = s }
+}
+
+func providerConfig(m ...providerConfigModifier) *v1alpha1.ProviderConfig {
+	pc := &v1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "bitbucket"},
+		Spec: v1alpha1.ProviderConfigSpec{
+			BaseURL: "https://bitbucket.example.org",
+			Credentials: v1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{
+						SecretReference: xpv1.SecretReference{Name: "bitbucket-creds", Namespace: "crossplane-system"},
+						Key:             "credentials",
+					},
+				},
+			},
+			Rotation: &v1alpha1.TokenRotation{Enabled: true, ExpiryDays: 30, Permissions: []string{"REPO_ADMIN"}},
+		},
+	}
+	for _, f := range m {
+		f(pc)
+	}
+	return pc
+}
+
+func secret(c clients.Credentials) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bitbucket-creds", Namespace: "crossplane-system"},
+		Data:       map[string][]byte{"credentials": c.Encode()},
+	}
+}
+
+func metaTime(t time.Time) *metav1.Time {
+	return &metav1.Time{Time: t}
+}
+
+func TestRotationReconcile(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	expiresSoon := now.Add(24 * time.Hour)
+	expiresLater := now.Add(30 * 24 * time.Hour)
+
+	type want struct {
+		next        time.Duration
+		creds       clients.Credentials
+		data        string
+		annotations map[string]string
+		status      *v1alpha1.TokenRotationStatus
+		conditions  []xpv1.Condition
+		revoked     []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		pc     *v1alpha1.ProviderConfig
+		tokens *fake.MockAccessTokenService
+		// data the Secret holds instead of the old credentials
+		data string
+		// annotations of the Secret
+		annotations map[string]string
+		// reject makes the client fail to authenticate with these credentials
+		reject *clients.Credentials
+		want   want
+	}{
+		"RotationDisabled": {
+			reason: "We should leave the token of a ProviderConfig that did not opt into rotation alone.",
+			pc:     providerConfig(withRotation(nil)),
+			tokens: &fake.MockAccessTokenService{},
+			want:   want{creds: oldCreds},
+		},
+		"NotSecretSource": {
+			reason: "We should report that rotation requires credentials from a Secret.",
+			pc:     providerConfig(withSource(xpv1.CredentialsSourceEnvironment)),
+			tokens: &fake.MockAccessTokenService{},
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.New(errNotSecretSource))},
+			},
+		},
+		"NoUsername": {
+			reason: "We should report that a bearer token without a username cannot be rotated.",
+			pc:     providerConfig(withAuthType(v1alpha1.AuthTypeBearer)),
+			tokens: &fake.MockAccessTokenService{},
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.New(errNoUsername))},
+			},
+		},
+		"RotateBeforeExpiry": {
+			reason: "We should not replace a token when replacements would be due as soon as they are created.",
+			pc:     providerConfig(withRotation(&v1alpha1.TokenRotation{Enabled: true, ExpiryDays: 5})),
+			tokens: &fake.MockAccessTokenService{},
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.New(errRotateBefore))},
+			},
+		},
+		"RotateBeforeEqualsExpiry": {
+			reason: "We should not replace a token when replacements would be due as soon as they are created.",
+			pc: providerConfig(withRotation(&v1alpha1.TokenRotation{
+				Enabled:      true,
+				ExpiryDays:   7,
+				RotateBefore: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+			})),
+			tokens: &fake.MockAccessTokenService{},
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.New(errRotateBefore))},
+			},
+		},
+		"NotDue": {
+			reason: "We should record the expiry of a token that is not due and wait until it is.",
+			pc:     providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"})),
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(_ context.Context, req *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.User != "admin" || req.ID != "1" {
+						return nil, bitbucket.ErrNotFound
+					}
+					return &bitbucket.AccessToken{ID: "1", ExpiryDate: expiresLater.UnixMilli()}, nil
+				},
+			},
+			want: want{
+				next:  expiresLater.Add(-defaultRotateBefore).Sub(now),
+				creds: oldCreds,
+				status: &v1alpha1.TokenRotationStatus{
+					TokenID:          "1",
+					ExpiryDate:       metaTime(expiresLater),
+					NextRotationTime: metaTime(expiresLater.Add(-defaultRotateBefore)),
+				},
+				conditions: []xpv1.Condition{v1alpha1.RotationSucceeded()},
+			},
+		},
+		"Due": {
+			reason: "We should replace a token that is due, write the replacement to the Secret and revoke the replaced token.",
+			pc:     providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"})),
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "1", ExpiryDate: expiresSoon.UnixMilli()}, nil
+				},
+				MockCreateAccessToken: func(_ context.Context, req *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.User != "admin" || req.ExpiryDays != 30 || len(req.Permissions) != 1 {
+						return nil, errBoom
+					}
+					return &bitbucket.AccessToken{ID: "2", Token: newCreds.Password, ExpiryDate: expiresLater.UnixMilli()}, nil
+				},
+			},
+			want: want{
+				next:        expiresLater.Add(-defaultRotateBefore).Sub(now),
+				creds:       newCreds,
+				annotations: map[string]string{AnnotationTokenID: "2"},
+				status: &v1alpha1.TokenRotationStatus{
+					TokenID:          "2",
+					ExpiryDate:       metaTime(expiresLater),
+					NextRotationTime: metaTime(expiresLater.Add(-defaultRotateBefore)),
+					LastRotationTime: metaTime(now),
+				},
+				conditions: []xpv1.Condition{v1alpha1.RotationSucceeded()},
+				revoked:    []string{"1"},
+			},
+		},
+		"UnknownToken": {
+			reason: "We should replace a token whose ID is not known right away, without revoking anything.",
+			pc:     providerConfig(),
+			tokens: &fake.MockAccessTokenService{
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "2", Token: newCreds.Password}, nil
+				},
+			},
+			want: want{
+				creds:       newCreds,
+				annotations: map[string]string{AnnotationTokenID: "2"},
+				status:      &v1alpha1.TokenRotationStatus{TokenID: "2", LastRotationTime: metaTime(now)},
+				conditions:  []xpv1.Condition{v1alpha1.RotationSucceeded()},
+			},
+		},
+		"StructuredCredentials": {
+			reason: "We should write the replacement token back in the JSON format the credentials were read in.",
+			pc:     providerConfig(),
+			data:   `{"username":"admin","token":"old-token"}`,
+			tokens: &fake.MockAccessTokenService{
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "2", Token: "new-token"}, nil
+				},
+			},
+			want: want{
+				data:        `{"username":"admin","token":"new-token"}`,
+				annotations: map[string]string{AnnotationTokenID: "2"},
+				status:      &v1alpha1.TokenRotationStatus{TokenID: "2", LastRotationTime: metaTime(now)},
+				conditions:  []xpv1.Condition{v1alpha1.RotationSucceeded()},
+			},
+		},
+		"SecretTokenID": {
+			reason:      "We should trust the token ID the Secret records over a status that was not updated after the last rotation.",
+			pc:          providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"})),
+			annotations: map[string]string{AnnotationTokenID: "2"},
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(_ context.Context, req *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					if req.ID != "2" {
+						return nil, bitbucket.ErrNotFound
+					}
+					return &bitbucket.AccessToken{ID: "2", ExpiryDate: expiresLater.UnixMilli()}, nil
+				},
+			},
+			want: want{
+				next:        expiresLater.Add(-defaultRotateBefore).Sub(now),
+				creds:       oldCreds,
+				annotations: map[string]string{AnnotationTokenID: "2"},
+				status: &v1alpha1.TokenRotationStatus{
+					TokenID:          "2",
+					ExpiryDate:       metaTime(expiresLater),
+					NextRotationTime: metaTime(expiresLater.Add(-defaultRotateBefore)),
+				},
+				conditions: []xpv1.Condition{v1alpha1.RotationSucceeded()},
+			},
+		},
+		"LeftoverToken": {
+			reason:      "We should revoke a token the Secret records as yet to be revoked, and clear the record.",
+			pc:          providerConfig(),
+			annotations: map[string]string{AnnotationTokenID: "2", AnnotationRevokeTokenID: "1"},
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "2"}, nil
+				},
+			},
+			want: want{
+				creds:       oldCreds,
+				annotations: map[string]string{AnnotationTokenID: "2"},
+				status:      &v1alpha1.TokenRotationStatus{TokenID: "2"},
+				conditions:  []xpv1.Condition{v1alpha1.RotationSucceeded()},
+				revoked:     []string{"1"},
+			},
+		},
+		"VerifyFailed": {
+			reason: "We should put the replaced token back and revoke the replacement when it does not authenticate.",
+			pc:     providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"})),
+			tokens: &fake.MockAccessTokenService{
+				MockGetAccessToken: func(context.Context, *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "1", ExpiryDate: expiresSoon.UnixMilli()}, nil
+				},
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return &bitbucket.AccessToken{ID: "2", Token: newCreds.Password}, nil
+				},
+			},
+			reject: &newCreds,
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				status:     &v1alpha1.TokenRotationStatus{TokenID: "1"},
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.Wrap(errBoom, errVerifyToken))},
+				revoked:    []string{"2"},
+			},
+		},
+		"CreateFailed": {
+			reason: "We should report errors creating the replacement token.",
+			pc:     providerConfig(),
+			tokens: &fake.MockAccessTokenService{
+				MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+					return nil, errBoom
+				},
+			},
+			want: want{
+				next:       shortWait,
+				creds:      oldCreds,
+				conditions: []xpv1.Condition{v1alpha1.RotationFailed(errors.Wrap(errBoom, errCreateToken))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sec := secret(oldCreds)
+			if tc.data != "" {
+				sec.Data["credentials"] = []byte(tc.data)
+			}
+			sec.SetAnnotations(tc.annotations)
+			kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(tc.pc, sec).Build()
+
+			revoked := []string{}
+			tc.tokens.MockDeleteAccessToken = func(_ context.Context, req *bitbucket.DeleteAccessTokenRequest) error {
+				revoked = append(revoked, req.ID)
+				return nil
+			}
+
+			r := &rotator{
+				kube:   kube,
+				log:    logging.NewNopLogger(),
+				record: event.NewNopRecorder(),
+				newClientFn: func(_ context.Context, _ string, creds clients.Credentials, _ ...bitbucket.Option) (*bitbucket.Client, error) {
+					if tc.reject != nil && creds == *tc.reject {
+						return nil, errBoom
+					}
+					return &bitbucket.Client{AccessTokens: tc.tokens}, nil
+				},
+				now: func() time.Time { return now },
+			}
+
+			pc := tc.pc.DeepCopy()
+			got := r.reconcile(context.Background(), pc)
+			if diff := cmp.Diff(tc.want.next, got); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			_ = kube.Get(context.Background(), types.NamespacedName{Name: "bitbucket-creds", Namespace: "crossplane-system"}, sec)
+			if tc.want.data == "" {
+				tc.want.data = string(tc.want.creds.Encode())
+			}
+			if diff := cmp.Diff(tc.want.data, string(sec.Data["credentials"])); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want credentials, +got credentials:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.annotations, sec.GetAnnotations(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want annotations, +got annotations:\n%s\n", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.status, pc.Status.Rotation); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, pc.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.revoked == nil {
+				tc.want.revoked = []string{}
+			}
+			if diff := cmp.Diff(tc.want.revoked, revoked); diff != "" {
+				t.Errorf("\n%s\nr.reconcile(...): -want revoked, +got revoked:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// statusFailingClient fails every status update.
+type statusFailingClient struct {
+	client.Client
+}
+
+func (c statusFailingClient) Status() client.StatusWriter {
+	return statusFailingWriter{c.Client.Status()}
+}
+
+type statusFailingWriter struct {
+	client.StatusWriter
+}
+
+func (statusFailingWriter) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return errBoom
+}
+
+func TestRotationStatusUpdateFailed(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+
+	expiresSoon := now.Add(24 * time.Hour)
+	expiresLater := now.Add(30 * 24 * time.Hour)
+
+	pc := providerConfig(withRotationStatus(&v1alpha1.TokenRotationStatus{TokenID: "1"}))
+	kube := kubefake.NewClientBuilder().WithScheme(s).WithObjects(pc, secret(oldCreds)).Build()
+
+	created, revoked := []string{}, []string{}
+	tokens := &fake.MockAccessTokenService{
+		MockGetAccessToken: func(_ context.Context, req *bitbucket.GetAccessTokenRequest) (*bitbucket.AccessToken, error) {
+			switch req.ID {
+			case "1":
+				return &bitbucket.AccessToken{ID: "1", ExpiryDate: expiresSoon.UnixMilli()}, nil
+			case "2":
+				return &bitbucket.AccessToken{ID: "2", ExpiryDate: expiresLater.UnixMilli()}, nil
+			}
+			return nil, bitbucket.ErrNotFound
+		},
+		MockCreateAccessToken: func(context.Context, *bitbucket.CreateAccessTokenRequest) (*bitbucket.AccessToken, error) {
+			id := strconv.Itoa(len(created) + 2)
+			created = append(created, id)
+			return &bitbucket.AccessToken{ID: id, Token: newCreds.Password, ExpiryDate: expiresLater.UnixMilli()}, nil
+		},
+		MockDeleteAccessToken: func(_ context.Context, req *bitbucket.DeleteAccessTokenRequest) error {
+			revoked = append(revoked, req.ID)
+			return nil
+		},
+	}
+
+	newClientFn := func(context.Context, string, clients.Credentials, ...bitbucket.Option) (*bitbucket.Client, error) {
+		return &bitbucket.Client{AccessTokens: tokens, Application: &fake.MockApplicationService{
+			MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+				return &bitbucket.ApplicationProperties{Version: "8.5.0", Username: "admin"}, nil
+			},
+		}}, nil
+	}
+	r := &healthReconciler{
+		usage: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) { return reconcile.Result{}, nil }),
+		rotation: &rotator{
+			kube:        kube,
+			log:         logging.NewNopLogger(),
+			record:      event.NewNopRecorder(),
+			newClientFn: newClientFn,
+			now:         func() time.Time { return now },
+		},
+		kube:         statusFailingClient{kube},
+		reader:       kube,
+		log:          logging.NewNopLogger(),
+		newClientFn:  newClientFn,
+		pollInterval: time.Minute,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: pc.GetName()}}
+
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("r.Reconcile(...): want an error updating the status, got none")
+	}
+
+	r.kube = kube
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("r.Reconcile(...): unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"2"}, created); diff != "" {
+		t.Errorf("We should not replace the token again after failing to record the replacement in the status.\n-want created, +got created:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"1"}, revoked); diff != "" {
+		t.Errorf("We should revoke the replaced token, and only that token.\n-want revoked, +got revoked:\n%s\n", diff)
+	}
+
+	got := &v1alpha1.ProviderConfig{}
+	_ = kube.Get(context.Background(), req.NamespacedName, got)
+	if got.Status.Rotation == nil || got.Status.Rotation.TokenID != "2" {
+		t.Errorf("We should record the replacement in the status once it can be updated, got %+v", got.Status.Rotation)
+	}
+}
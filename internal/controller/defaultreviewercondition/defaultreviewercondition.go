@@ -0,0 +1,279 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultreviewercondition
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/pullrequest/v1alpha1"
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/compare"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/refmatcher"
+)
+
+const (
+	errNotDefaultReviewerCondition = "managed resource is not a DefaultReviewerCondition custom resource"
+	errTrackPCUsage                = "cannot track ProviderConfig usage"
+
+	errNewClient = "cannot create new Service"
+
+	errInvalidID       = "external name is not a valid default reviewer condition ID"
+	errFindReviewer    = "cannot find reviewer"
+	errGetCondition    = "cannot get default reviewer condition"
+	errCreateCondition = "cannot create default reviewer condition"
+	errUpdateCondition = "cannot update default reviewer condition"
+	errDeleteCondition = "cannot delete default reviewer condition"
+)
+
+// Setup adds a controller that reconciles DefaultReviewerCondition managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.DefaultReviewerConditionGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DefaultReviewerConditionGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.DefaultReviewerCondition{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DefaultReviewerCondition)
+	if !ok {
+		return nil, errors.New(errNotDefaultReviewerCondition)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{reviewers: bc.DefaultReviewers, users: bc.Users}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external struct {
+	// reviewers is used to manage default reviewer conditions through the bitbucket api.
+	reviewers bitbucket.DefaultReviewerService
+	// users is used to look up the IDs of the reviewers.
+	users bitbucket.UserService
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DefaultReviewerCondition)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDefaultReviewerCondition)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errInvalidID)
+	}
+
+	cond, err := c.reviewers.GetCondition(ctx, &bitbucket.GetConditionRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCondition)
+	}
+
+	cr.Status.AtProvider.ID = cond.ID
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  isUpToDate(cr.Spec.ForProvider, cond),
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DefaultReviewerCondition)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDefaultReviewerCondition)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	reviewers, err := c.findReviewers(ctx, cr.Spec.ForProvider.Users)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	cond, err := c.reviewers.CreateCondition(ctx, &bitbucket.CreateConditionRequest{
+		ProjectKey:        cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug:    cr.Spec.ForProvider.RepositorySlug,
+		SourceMatcher:     refmatcher.Generate(cr.Spec.ForProvider.SourceMatcher.Type, cr.Spec.ForProvider.SourceMatcher.ID),
+		TargetMatcher:     refmatcher.Generate(cr.Spec.ForProvider.TargetMatcher.Type, cr.Spec.ForProvider.TargetMatcher.ID),
+		Reviewers:         reviewers,
+		RequiredApprovals: cr.Spec.ForProvider.RequiredApprovals,
+	})
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCondition)
+	}
+
+	meta.SetExternalName(cr, strconv.Itoa(cond.ID))
+
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DefaultReviewerCondition)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDefaultReviewerCondition)
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvalidID)
+	}
+
+	reviewers, err := c.findReviewers(ctx, cr.Spec.ForProvider.Users)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	_, err = c.reviewers.UpdateCondition(ctx, &bitbucket.UpdateConditionRequest{
+		ProjectKey:        cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug:    cr.Spec.ForProvider.RepositorySlug,
+		ID:                id,
+		SourceMatcher:     refmatcher.Generate(cr.Spec.ForProvider.SourceMatcher.Type, cr.Spec.ForProvider.SourceMatcher.ID),
+		TargetMatcher:     refmatcher.Generate(cr.Spec.ForProvider.TargetMatcher.Type, cr.Spec.ForProvider.TargetMatcher.ID),
+		Reviewers:         reviewers,
+		RequiredApprovals: cr.Spec.ForProvider.RequiredApprovals,
+	})
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrap(err, errUpdateCondition)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DefaultReviewerCondition)
+	if !ok {
+		return errors.New(errNotDefaultReviewerCondition)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return errors.Wrap(err, errInvalidID)
+	}
+
+	err = c.reviewers.DeleteCondition(ctx, &bitbucket.DeleteConditionRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteCondition)
+	}
+
+	return nil
+}
+
+// findReviewers looks up the IDs of the users to add as reviewers, which the
+// api requires instead of their names.
+func (c *external) findReviewers(ctx context.Context, names []string) ([]bitbucket.Reviewer, error) {
+	reviewers := make([]bitbucket.Reviewer, len(names))
+	for i, n := range names {
+		u, err := c.users.FindUser(ctx, &bitbucket.FindUserRequest{Name: n})
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %s", errFindReviewer, n)
+		}
+		reviewers[i] = bitbucket.Reviewer{ID: u.ID}
+	}
+	return reviewers, nil
+}
+
+func isUpToDate(p v1alpha1.DefaultReviewerConditionParameters, cond *bitbucket.Condition) bool {
+	if !refmatcher.Matches(p.SourceMatcher.Type, p.SourceMatcher.ID, cond.SourceMatcher) || !refmatcher.Matches(p.TargetMatcher.Type, p.TargetMatcher.ID, cond.TargetMatcher) {
+		return false
+	}
+	if p.RequiredApprovals != cond.RequiredApprovals {
+		return false
+	}
+
+	want := make([]string, len(p.Users))
+	for i, u := range p.Users {
+		want[i] = strings.ToLower(u)
+	}
+	got := make([]string, len(cond.Reviewers))
+	for i, u := range cond.Reviewers {
+		got[i] = strings.ToLower(u.Name)
+	}
+	return compare.SameSet(want, got)
+}
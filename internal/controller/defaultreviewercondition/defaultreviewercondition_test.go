@@ -0,0 +1,457 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultreviewercondition
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/pullrequest/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	projectKey = "PRJ"
+	slug       = "my-repo"
+)
+
+type conditionModifier func(*v1alpha1.DefaultReviewerCondition)
+
+func withExternalName(n string) conditionModifier {
+	return func(c *v1alpha1.DefaultReviewerCondition) { meta.SetExternalName(c, n) }
+}
+
+func withUsers(u ...string) conditionModifier {
+	return func(c *v1alpha1.DefaultReviewerCondition) { c.Spec.ForProvider.Users = u }
+}
+
+func withRequiredApprovals(n int) conditionModifier {
+	return func(c *v1alpha1.DefaultReviewerCondition) { c.Spec.ForProvider.RequiredApprovals = n }
+}
+
+func withConditions(c ...xpv1.Condition) conditionModifier {
+	return func(cr *v1alpha1.DefaultReviewerCondition) { cr.Status.SetConditions(c...) }
+}
+
+func withID(id int) conditionModifier {
+	return func(c *v1alpha1.DefaultReviewerCondition) { c.Status.AtProvider.ID = id }
+}
+
+func condition(m ...conditionModifier) *v1alpha1.DefaultReviewerCondition {
+	cr := &v1alpha1.DefaultReviewerCondition{
+		Spec: v1alpha1.DefaultReviewerConditionSpec{
+			ForProvider: v1alpha1.DefaultReviewerConditionParameters{
+				ProjectKey:     projectKey,
+				RepositorySlug: slug,
+				SourceMatcher:  v1alpha1.RefMatcher{Type: bitbucket.MatcherAnyRef},
+				TargetMatcher:  v1alpha1.RefMatcher{Type: bitbucket.MatcherBranch, ID: "main"},
+				Users:          []string{"alice", "bob"},
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.DefaultReviewerCondition
+		err error
+	}
+
+	observed := &bitbucket.Condition{
+		ID: 4,
+		SourceMatcher: bitbucket.RefMatcher{
+			ID:   bitbucket.AnyRefMatcherID,
+			Type: bitbucket.RefMatcherType{ID: bitbucket.MatcherAnyRef},
+		},
+		TargetMatcher: bitbucket.RefMatcher{
+			ID:   "refs/heads/main",
+			Type: bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch},
+		},
+		Reviewers: []bitbucket.User{{Name: "Bob"}, {Name: "alice"}},
+	}
+
+	cases := map[string]struct {
+		reason    string
+		reviewers bitbucket.DefaultReviewerService
+		args      args
+		want      want
+	}{
+		"NotCreated": {
+			reason: "We should report a condition without an ID as not existing.",
+			args:   args{ctx: context.Background(), mg: condition()},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: condition(),
+			},
+		},
+		"NotFound": {
+			reason: "We should report a deleted condition as not existing.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockGetCondition: func(context.Context, *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			},
+			args: args{ctx: context.Background(), mg: condition(withExternalName("4"))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: condition(withExternalName("4")),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the condition.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockGetCondition: func(context.Context, *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: condition(withExternalName("4"))},
+			want: want{
+				cr:  condition(withExternalName("4")),
+				err: errors.Wrap(errBoom, errGetCondition),
+			},
+		},
+		"UpToDate": {
+			reason: "We should ignore the order and case of the reviewers and normalize the matchers.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockGetCondition: func(_ context.Context, req *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+					if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 4 {
+						return nil, bitbucket.ErrNotFound
+					}
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: condition(withExternalName("4"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: condition(withExternalName("4"), withConditions(xpv1.Available()), withID(4)),
+			},
+		},
+		"ReviewersDrift": {
+			reason: "We should report a condition adding other reviewers as not up to date.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockGetCondition: func(context.Context, *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: condition(withExternalName("4"), withUsers("alice"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: condition(withExternalName("4"), withUsers("alice"), withConditions(xpv1.Available()), withID(4)),
+			},
+		},
+		"RequiredApprovalsDrift": {
+			reason: "We should report a condition requiring another number of approvals as not up to date.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockGetCondition: func(context.Context, *bitbucket.GetConditionRequest) (*bitbucket.Condition, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: condition(withExternalName("4"), withRequiredApprovals(1))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: condition(withExternalName("4"), withRequiredApprovals(1), withConditions(xpv1.Available()), withID(4)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{reviewers: tc.reviewers}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	users := &fake.MockUserService{
+		MockFindUser: func(_ context.Context, req *bitbucket.FindUserRequest) (*bitbucket.User, error) {
+			switch req.Name {
+			case "alice":
+				return &bitbucket.User{ID: 1, Name: "alice"}, nil
+			case "bob":
+				return &bitbucket.User{ID: 2, Name: "bob"}, nil
+			}
+			return nil, bitbucket.ErrNotFound
+		},
+	}
+
+	type want struct {
+		cr  *v1alpha1.DefaultReviewerCondition
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		reviewers bitbucket.DefaultReviewerService
+		mg        resource.Managed
+		want      want
+	}{
+		"Created": {
+			reason: "We should add the reviewers by ID and use the ID of the condition as external name.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockCreateCondition: func(_ context.Context, req *bitbucket.CreateConditionRequest) (*bitbucket.Condition, error) {
+					want := &bitbucket.CreateConditionRequest{
+						ProjectKey:     projectKey,
+						RepositorySlug: slug,
+						SourceMatcher: bitbucket.RefMatcher{
+							ID:        bitbucket.AnyRefMatcherID,
+							DisplayID: bitbucket.AnyRefMatcherID,
+							Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherAnyRef},
+							Active:    true,
+						},
+						TargetMatcher: bitbucket.RefMatcher{
+							ID:        "refs/heads/main",
+							DisplayID: "main",
+							Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch},
+							Active:    true,
+						},
+						Reviewers: []bitbucket.Reviewer{{ID: 1}, {ID: 2}},
+					}
+					if diff := cmp.Diff(want, req); diff != "" {
+						t.Errorf("CreateCondition(...): -want, +got:\n%s", diff)
+					}
+					return &bitbucket.Condition{ID: 4}, nil
+				},
+			},
+			mg:   condition(),
+			want: want{cr: condition(withExternalName("4"), withConditions(xpv1.Creating()))},
+		},
+		"UnknownReviewer": {
+			reason: "We should return an error if a reviewer does not exist.",
+			mg:     condition(withUsers("carol")),
+			want: want{
+				cr:  condition(withUsers("carol"), withConditions(xpv1.Creating())),
+				err: errors.Wrap(bitbucket.ErrNotFound, errFindReviewer+" carol"),
+			},
+		},
+		"CreateFailed": {
+			reason: "We should return errors creating the condition.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockCreateCondition: func(context.Context, *bitbucket.CreateConditionRequest) (*bitbucket.Condition, error) {
+					return nil, errBoom
+				},
+			},
+			mg: condition(),
+			want: want{
+				cr:  condition(withConditions(xpv1.Creating())),
+				err: errors.Wrap(errBoom, errCreateCondition),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{reviewers: tc.reviewers, users: users}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	users := &fake.MockUserService{
+		MockFindUser: func(_ context.Context, req *bitbucket.FindUserRequest) (*bitbucket.User, error) {
+			switch req.Name {
+			case "alice":
+				return &bitbucket.User{ID: 1, Name: "alice"}, nil
+			case "bob":
+				return &bitbucket.User{ID: 2, Name: "bob"}, nil
+			}
+			return nil, bitbucket.ErrNotFound
+		},
+	}
+
+	cases := map[string]struct {
+		reason    string
+		reviewers bitbucket.DefaultReviewerService
+		mg        resource.Managed
+		want      error
+	}{
+		"Updated": {
+			reason: "We should update the condition of the external name with the desired reviewers by ID.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockUpdateCondition: func(_ context.Context, req *bitbucket.UpdateConditionRequest) (*bitbucket.Condition, error) {
+					want := &bitbucket.UpdateConditionRequest{
+						ProjectKey:     projectKey,
+						RepositorySlug: slug,
+						ID:             4,
+						SourceMatcher: bitbucket.RefMatcher{
+							ID:        bitbucket.AnyRefMatcherID,
+							DisplayID: bitbucket.AnyRefMatcherID,
+							Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherAnyRef},
+							Active:    true,
+						},
+						TargetMatcher: bitbucket.RefMatcher{
+							ID:        "refs/heads/main",
+							DisplayID: "main",
+							Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch},
+							Active:    true,
+						},
+						Reviewers:         []bitbucket.Reviewer{{ID: 2}},
+						RequiredApprovals: 1,
+					}
+					if diff := cmp.Diff(want, req); diff != "" {
+						return nil, errors.New(diff)
+					}
+					return &bitbucket.Condition{ID: 4}, nil
+				},
+			},
+			mg: condition(withExternalName("4"), withUsers("bob"), withRequiredApprovals(1)),
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			mg:     condition(withExternalName("main")),
+			want:   errors.Wrap(errors.New(`strconv.Atoi: parsing "main": invalid syntax`), errInvalidID),
+		},
+		"UnknownReviewer": {
+			reason: "We should return an error if a reviewer does not exist.",
+			mg:     condition(withExternalName("4"), withUsers("carol")),
+			want:   errors.Wrap(bitbucket.ErrNotFound, errFindReviewer+" carol"),
+		},
+		"UpdateFailed": {
+			reason: "We should return errors updating the condition.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockUpdateCondition: func(context.Context, *bitbucket.UpdateConditionRequest) (*bitbucket.Condition, error) {
+					return nil, errBoom
+				},
+			},
+			mg:   condition(withExternalName("4")),
+			want: errors.Wrap(errBoom, errUpdateCondition),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{reviewers: tc.reviewers, users: users}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		reviewers bitbucket.DefaultReviewerService
+		mg        resource.Managed
+		want      error
+	}{
+		"Deleted": {
+			reason: "We should delete the condition of the external name.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockDeleteCondition: func(_ context.Context, req *bitbucket.DeleteConditionRequest) error {
+					if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 4 {
+						return errBoom
+					}
+					return nil
+				},
+			},
+			mg: condition(withExternalName("4")),
+		},
+		"AlreadyDeleted": {
+			reason: "We should not return an error when the condition is already gone.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockDeleteCondition: func(context.Context, *bitbucket.DeleteConditionRequest) error {
+					return bitbucket.ErrNotFound
+				},
+			},
+			mg: condition(withExternalName("4")),
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			mg:     condition(withExternalName("main")),
+			want:   errors.Wrap(errors.New(`strconv.Atoi: parsing "main": invalid syntax`), errInvalidID),
+		},
+		"DeleteFailed": {
+			reason: "We should return errors deleting the condition.",
+			reviewers: &fake.MockDefaultReviewerService{
+				MockDeleteCondition: func(context.Context, *bitbucket.DeleteConditionRequest) error {
+					return errBoom
+				},
+			},
+			mg:   condition(withExternalName("4")),
+			want: errors.Wrap(errBoom, errDeleteCondition),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{reviewers: tc.reviewers}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
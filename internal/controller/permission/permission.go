@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permission reconciles the permissions users and groups are granted
+// on projects and repositories. The managed resources of each scope share
+// this controller, and only tell it which permission they grant.
+package permission
+
+import (
+	"context"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+)
+
+const (
+	errNotKind      = "managed resource is not a %s custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+
+	errNewClient = "cannot create new Service"
+
+	errGetPermission    = "cannot get %s"
+	errSetPermission    = "cannot set %s"
+	errRevokePermission = "cannot revoke %s"
+)
+
+// A Grant is a permission granted to a user or a group on a project, or on a
+// repository of a project.
+type Grant struct {
+	ProjectKey string
+
+	// RepositorySlug is the repository the permission is granted on, it is
+	// empty for permissions granted on the project itself.
+	RepositorySlug string
+
+	// User or Group is who the permission is granted to.
+	User  string
+	Group string
+
+	// Permission is the level of the permission, e.g. PROJECT_READ.
+	Permission string
+}
+
+// A Scope tells the controller about the permission managed resources of type
+// T grant.
+type Scope[T resource.Managed] struct {
+	// Kind of the managed resources.
+	Kind string
+
+	// Description names the permission in errors, e.g. project user
+	// permission.
+	Description string
+
+	// Grant returns the permission the managed resource grants.
+	Grant func(cr T) Grant
+
+	// Observe records the level of the permission that is granted in the
+	// status of the managed resource.
+	Observe func(cr T, permission string)
+}
+
+// Setup adds a controller that reconciles the permission managed resources of
+// type T, of which obj is an instance.
+func Setup[T resource.Managed](mgr ctrl.Manager, o controller.Options, gvk schema.GroupVersionKind, obj T, s Scope[T]) error {
+	name := managed.ControllerName(gvk.GroupKind().String())
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(gvk),
+		managed.WithExternalConnecter(&connector[T]{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient,
+			scope:       s}),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(obj).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector[T resource.Managed] struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+	scope       Scope[T]
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector[T]) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return nil, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external[T]{permissions: bc.Permissions, scope: c.scope}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external[T resource.Managed] struct {
+	// permissions is used to manage permissions through the bitbucket api.
+	permissions bitbucket.PermissionService
+
+	scope Scope[T]
+}
+
+func (c *external[T]) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalObservation{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	g := c.scope.Grant(cr)
+	perm, err := get(ctx, c.permissions, g)
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrapf(err, errGetPermission, c.scope.Description)
+	}
+
+	c.scope.Observe(cr, perm)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  perm == g.Permission,
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (c *external[T]) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalCreation{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	err := set(ctx, c.permissions, c.scope.Grant(cr))
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrapf(err, errSetPermission, c.scope.Description)
+}
+
+func (c *external[T]) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	err := set(ctx, c.permissions, c.scope.Grant(cr))
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrapf(err, errSetPermission, c.scope.Description)
+}
+
+func (c *external[T]) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(T)
+	if !ok {
+		return errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	err := revoke(ctx, c.permissions, c.scope.Grant(cr))
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrapf(err, errRevokePermission, c.scope.Description)
+	}
+
+	return nil
+}
+
+// get returns the level of the permission the grantee of g holds.
+func get(ctx context.Context, ps bitbucket.PermissionService, g Grant) (string, error) {
+	var (
+		up  *bitbucket.UserPermission
+		gp  *bitbucket.GroupPermission
+		err error
+	)
+	switch {
+	case g.RepositorySlug == "" && g.Group != "":
+		gp, err = ps.GetProjectGroupPermission(ctx, &bitbucket.GetProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.Group})
+	case g.RepositorySlug == "":
+		up, err = ps.GetProjectUserPermission(ctx, &bitbucket.GetProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.User})
+	case g.Group != "":
+		gp, err = ps.GetRepositoryGroupPermission(ctx, &bitbucket.GetRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.Group})
+	default:
+		up, err = ps.GetRepositoryUserPermission(ctx, &bitbucket.GetRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.User})
+	}
+	switch {
+	case err != nil:
+		return "", err
+	case gp != nil:
+		return gp.Permission, nil
+	}
+	return up.Permission, nil
+}
+
+// set grants the permission of g, replacing any permission the grantee
+// already had.
+func set(ctx context.Context, ps bitbucket.PermissionService, g Grant) error {
+	switch {
+	case g.RepositorySlug == "" && g.Group != "":
+		return ps.SetProjectGroupPermission(ctx, &bitbucket.SetProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.Group, Permission: g.Permission})
+	case g.RepositorySlug == "":
+		return ps.SetProjectUserPermission(ctx, &bitbucket.SetProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.User, Permission: g.Permission})
+	case g.Group != "":
+		return ps.SetRepositoryGroupPermission(ctx, &bitbucket.SetRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.Group, Permission: g.Permission})
+	default:
+		return ps.SetRepositoryUserPermission(ctx, &bitbucket.SetRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.User, Permission: g.Permission})
+	}
+}
+
+// revoke revokes any permission the grantee of g holds.
+func revoke(ctx context.Context, ps bitbucket.PermissionService, g Grant) error {
+	switch {
+	case g.RepositorySlug == "" && g.Group != "":
+		return ps.RevokeProjectGroupPermission(ctx, &bitbucket.RevokeProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.Group})
+	case g.RepositorySlug == "":
+		return ps.RevokeProjectUserPermission(ctx, &bitbucket.RevokeProjectPermissionRequest{ProjectKey: g.ProjectKey, Name: g.User})
+	case g.Group != "":
+		return ps.RevokeRepositoryGroupPermission(ctx, &bitbucket.RevokeRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.Group})
+	default:
+		return ps.RevokeRepositoryUserPermission(ctx, &bitbucket.RevokeRepositoryPermissionRequest{ProjectKey: g.ProjectKey, RepositorySlug: g.RepositorySlug, Name: g.User})
+	}
+}
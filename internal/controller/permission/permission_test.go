@@ -0,0 +1,428 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permission
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	projectKey = "PRJ"
+	grantee    = "jdoe"
+
+	// scope is the scope of the managed resources the tests reconcile, the
+	// scopes of the other kinds are tested by their packages.
+	scope = Scope[*v1alpha1.ProjectUserPermission]{
+		Kind:        v1alpha1.ProjectUserPermissionKind,
+		Description: "project user permission",
+		Grant: func(cr *v1alpha1.ProjectUserPermission) Grant {
+			return Grant{ProjectKey: cr.Spec.ForProvider.ProjectKey, User: cr.Spec.ForProvider.User, Permission: cr.Spec.ForProvider.Permission}
+		},
+		Observe: func(cr *v1alpha1.ProjectUserPermission, perm string) {
+			cr.Status.AtProvider.Permission = perm
+		},
+	}
+)
+
+type permissionModifier func(*v1alpha1.ProjectUserPermission)
+
+func withConditions(c ...xpv1.Condition) permissionModifier {
+	return func(p *v1alpha1.ProjectUserPermission) { p.Status.SetConditions(c...) }
+}
+
+func withObservedPermission(perm string) permissionModifier {
+	return func(p *v1alpha1.ProjectUserPermission) { p.Status.AtProvider.Permission = perm }
+}
+
+func permission(m ...permissionModifier) *v1alpha1.ProjectUserPermission {
+	cr := &v1alpha1.ProjectUserPermission{
+		Spec: v1alpha1.ProjectUserPermissionSpec{
+			ForProvider: v1alpha1.ProjectUserPermissionParameters{
+				ProjectKey: projectKey,
+				User:       grantee,
+				Permission: "PROJECT_WRITE",
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type fields struct {
+		permissions bitbucket.PermissionService
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.ProjectUserPermission
+		err error
+	}
+
+	granted := func(perm string) func(context.Context, *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+		return func(_ context.Context, req *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+			if req.ProjectKey != projectKey || req.Name != grantee {
+				return nil, bitbucket.ErrNotFound
+			}
+			return &bitbucket.UserPermission{User: bitbucket.User{Name: grantee}, Permission: perm}, nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"NotGranted": {
+			reason: "We should report a permission that has not been granted as not existing.",
+			fields: fields{permissions: &fake.MockPermissionService{
+				MockGetProjectUserPermission: func(context.Context, *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			}},
+			args: args{ctx: context.Background(), mg: permission()},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: permission(),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the permission.",
+			fields: fields{permissions: &fake.MockPermissionService{
+				MockGetProjectUserPermission: func(context.Context, *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+					return nil, errBoom
+				},
+			}},
+			args: args{ctx: context.Background(), mg: permission()},
+			want: want{
+				cr:  permission(),
+				err: errors.Wrap(errBoom, "cannot get project user permission"),
+			},
+		},
+		"UpToDate": {
+			reason: "We should report a permission granted at the desired level as up to date.",
+			fields: fields{permissions: &fake.MockPermissionService{MockGetProjectUserPermission: granted("PROJECT_WRITE")}},
+			args:   args{ctx: context.Background(), mg: permission()},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: permission(withConditions(xpv1.Available()), withObservedPermission("PROJECT_WRITE")),
+			},
+		},
+		"LevelChanged": {
+			reason: "We should report a permission granted at a different level as not up to date.",
+			fields: fields{permissions: &fake.MockPermissionService{MockGetProjectUserPermission: granted("PROJECT_ADMIN")}},
+			args:   args{ctx: context.Background(), mg: permission()},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: permission(withConditions(xpv1.Available()), withObservedPermission("PROJECT_ADMIN")),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external[*v1alpha1.ProjectUserPermission]{permissions: tc.fields.permissions, scope: scope}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		permissions bitbucket.PermissionService
+		want        error
+	}{
+		"Restored": {
+			reason: "We should grant the desired permission level again.",
+			permissions: &fake.MockPermissionService{
+				MockSetProjectUserPermission: func(_ context.Context, req *bitbucket.SetProjectPermissionRequest) error {
+					if req.ProjectKey != projectKey || req.Name != grantee || req.Permission != "PROJECT_WRITE" {
+						return errBoom
+					}
+					return nil
+				},
+			},
+		},
+		"SetFailed": {
+			reason: "We should return errors setting the permission.",
+			permissions: &fake.MockPermissionService{
+				MockSetProjectUserPermission: func(context.Context, *bitbucket.SetProjectPermissionRequest) error {
+					return errBoom
+				},
+			},
+			want: errors.Wrap(errBoom, "cannot set project user permission"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external[*v1alpha1.ProjectUserPermission]{permissions: tc.permissions, scope: scope}
+			_, err := e.Update(context.Background(), permission())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		permissions bitbucket.PermissionService
+		want        error
+	}{
+		"Revoked": {
+			reason: "We should revoke the permission of the grantee.",
+			permissions: &fake.MockPermissionService{
+				MockRevokeProjectUserPermission: func(_ context.Context, req *bitbucket.RevokeProjectPermissionRequest) error {
+					if req.ProjectKey != projectKey || req.Name != grantee {
+						return errBoom
+					}
+					return nil
+				},
+			},
+		},
+		"AlreadyRevoked": {
+			reason: "We should not return an error when the permission is already gone.",
+			permissions: &fake.MockPermissionService{
+				MockRevokeProjectUserPermission: func(context.Context, *bitbucket.RevokeProjectPermissionRequest) error {
+					return bitbucket.ErrNotFound
+				},
+			},
+		},
+		"RevokeFailed": {
+			reason: "We should return errors revoking the permission.",
+			permissions: &fake.MockPermissionService{
+				MockRevokeProjectUserPermission: func(context.Context, *bitbucket.RevokeProjectPermissionRequest) error {
+					return errBoom
+				},
+			},
+			want: errors.Wrap(errBoom, "cannot revoke project user permission"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external[*v1alpha1.ProjectUserPermission]{permissions: tc.permissions, scope: scope}
+			err := e.Delete(context.Background(), permission())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// recorder is a permission service that records which of its methods was
+// called with what request.
+func recorder(called *string, req *interface{}) *fake.MockPermissionService {
+	rec := func(name string, r interface{}) {
+		*called = name
+		*req = r
+	}
+	return &fake.MockPermissionService{
+		MockGetProjectUserPermission: func(_ context.Context, r *bitbucket.GetProjectPermissionRequest) (*bitbucket.UserPermission, error) {
+			rec("GetProjectUserPermission", *r)
+			return &bitbucket.UserPermission{Permission: "PROJECT_READ"}, nil
+		},
+		MockSetProjectUserPermission: func(_ context.Context, r *bitbucket.SetProjectPermissionRequest) error {
+			rec("SetProjectUserPermission", *r)
+			return nil
+		},
+		MockRevokeProjectUserPermission: func(_ context.Context, r *bitbucket.RevokeProjectPermissionRequest) error {
+			rec("RevokeProjectUserPermission", *r)
+			return nil
+		},
+		MockGetProjectGroupPermission: func(_ context.Context, r *bitbucket.GetProjectPermissionRequest) (*bitbucket.GroupPermission, error) {
+			rec("GetProjectGroupPermission", *r)
+			return &bitbucket.GroupPermission{Permission: "PROJECT_READ"}, nil
+		},
+		MockSetProjectGroupPermission: func(_ context.Context, r *bitbucket.SetProjectPermissionRequest) error {
+			rec("SetProjectGroupPermission", *r)
+			return nil
+		},
+		MockRevokeProjectGroupPermission: func(_ context.Context, r *bitbucket.RevokeProjectPermissionRequest) error {
+			rec("RevokeProjectGroupPermission", *r)
+			return nil
+		},
+		MockGetRepositoryUserPermission: func(_ context.Context, r *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.UserPermission, error) {
+			rec("GetRepositoryUserPermission", *r)
+			return &bitbucket.UserPermission{Permission: "REPO_READ"}, nil
+		},
+		MockSetRepositoryUserPermission: func(_ context.Context, r *bitbucket.SetRepositoryPermissionRequest) error {
+			rec("SetRepositoryUserPermission", *r)
+			return nil
+		},
+		MockRevokeRepositoryUserPermission: func(_ context.Context, r *bitbucket.RevokeRepositoryPermissionRequest) error {
+			rec("RevokeRepositoryUserPermission", *r)
+			return nil
+		},
+		MockGetRepositoryGroupPermission: func(_ context.Context, r *bitbucket.GetRepositoryPermissionRequest) (*bitbucket.GroupPermission, error) {
+			rec("GetRepositoryGroupPermission", *r)
+			return &bitbucket.GroupPermission{Permission: "REPO_READ"}, nil
+		},
+		MockSetRepositoryGroupPermission: func(_ context.Context, r *bitbucket.SetRepositoryPermissionRequest) error {
+			rec("SetRepositoryGroupPermission", *r)
+			return nil
+		},
+		MockRevokeRepositoryGroupPermission: func(_ context.Context, r *bitbucket.RevokeRepositoryPermissionRequest) error {
+			rec("RevokeRepositoryGroupPermission", *r)
+			return nil
+		},
+	}
+}
+
+func TestScopes(t *testing.T) {
+	type call struct {
+		method string
+		req    interface{}
+	}
+
+	type want struct {
+		permission string
+		get        call
+		set        call
+		revoke     call
+	}
+
+	cases := map[string]struct {
+		reason string
+		grant  Grant
+		want   want
+	}{
+		"ProjectUser": {
+			reason: "We should manage the permission of a user on a project through the project user apis.",
+			grant:  Grant{ProjectKey: projectKey, User: grantee, Permission: "PROJECT_WRITE"},
+			want: want{
+				permission: "PROJECT_READ",
+				get:        call{"GetProjectUserPermission", bitbucket.GetProjectPermissionRequest{ProjectKey: projectKey, Name: grantee}},
+				set:        call{"SetProjectUserPermission", bitbucket.SetProjectPermissionRequest{ProjectKey: projectKey, Name: grantee, Permission: "PROJECT_WRITE"}},
+				revoke:     call{"RevokeProjectUserPermission", bitbucket.RevokeProjectPermissionRequest{ProjectKey: projectKey, Name: grantee}},
+			},
+		},
+		"ProjectGroup": {
+			reason: "We should manage the permission of a group on a project through the project group apis.",
+			grant:  Grant{ProjectKey: projectKey, Group: "devs", Permission: "PROJECT_WRITE"},
+			want: want{
+				permission: "PROJECT_READ",
+				get:        call{"GetProjectGroupPermission", bitbucket.GetProjectPermissionRequest{ProjectKey: projectKey, Name: "devs"}},
+				set:        call{"SetProjectGroupPermission", bitbucket.SetProjectPermissionRequest{ProjectKey: projectKey, Name: "devs", Permission: "PROJECT_WRITE"}},
+				revoke:     call{"RevokeProjectGroupPermission", bitbucket.RevokeProjectPermissionRequest{ProjectKey: projectKey, Name: "devs"}},
+			},
+		},
+		"RepositoryUser": {
+			reason: "We should manage the permission of a user on a repository through the repository user apis.",
+			grant:  Grant{ProjectKey: projectKey, RepositorySlug: "repo", User: grantee, Permission: "REPO_WRITE"},
+			want: want{
+				permission: "REPO_READ",
+				get:        call{"GetRepositoryUserPermission", bitbucket.GetRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: grantee}},
+				set:        call{"SetRepositoryUserPermission", bitbucket.SetRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: grantee, Permission: "REPO_WRITE"}},
+				revoke:     call{"RevokeRepositoryUserPermission", bitbucket.RevokeRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: grantee}},
+			},
+		},
+		"RepositoryGroup": {
+			reason: "We should manage the permission of a group on a repository through the repository group apis.",
+			grant:  Grant{ProjectKey: projectKey, RepositorySlug: "repo", Group: "devs", Permission: "REPO_WRITE"},
+			want: want{
+				permission: "REPO_READ",
+				get:        call{"GetRepositoryGroupPermission", bitbucket.GetRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: "devs"}},
+				set:        call{"SetRepositoryGroupPermission", bitbucket.SetRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: "devs", Permission: "REPO_WRITE"}},
+				revoke:     call{"RevokeRepositoryGroupPermission", bitbucket.RevokeRepositoryPermissionRequest{ProjectKey: projectKey, RepositorySlug: "repo", Name: "devs"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			got := call{}
+			ps := recorder(&got.method, &got.req)
+
+			perm, err := get(ctx, ps, tc.grant)
+			if err != nil {
+				t.Fatalf("\n%s\nget(...): unexpected error: %s\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.permission, perm); diff != "" {
+				t.Errorf("\n%s\nget(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.get, got, cmp.AllowUnexported(call{})); diff != "" {
+				t.Errorf("\n%s\nget(...): -want call, +got call:\n%s\n", tc.reason, diff)
+			}
+
+			if err := set(ctx, ps, tc.grant); err != nil {
+				t.Fatalf("\n%s\nset(...): unexpected error: %s\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.set, got, cmp.AllowUnexported(call{})); diff != "" {
+				t.Errorf("\n%s\nset(...): -want call, +got call:\n%s\n", tc.reason, diff)
+			}
+
+			if err := revoke(ctx, ps, tc.grant); err != nil {
+				t.Fatalf("\n%s\nrevoke(...): unexpected error: %s\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.revoke, got, cmp.AllowUnexported(call{})); diff != "" {
+				t.Errorf("\n%s\nrevoke(...): -want call, +got call:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -30,39 +30,23 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
 	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
 	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
 	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
 )
 
 const (
 	errNotProject   = "managed resource is not a Project custom resource"
 	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
+	errUpdateCR     = "cannot update Project custom resource"
 
 	errNewClient = "cannot create new Service"
-)
-
-// A BitbucketService provides operations against bitbucket
-type BitbucketService struct {
-	Client *bitbucket.Client
-}
-
-var (
-	newBitbucketService = func(baseURL string, creds []byte) (*BitbucketService, error) {
-		c, err := bitbucket.NewClient(baseURL, string(creds))
-		if err != nil {
-			// crash if we get an error setting up client
-			log.Fatalln(err)
-		}
-		return &BitbucketService{Client: c}, err
-	}
+	errKeyTaken  = "project at the new key is not the project of this resource"
 )
 
 // Setup adds a controller that reconciles Project managed resources.
@@ -78,9 +62,10 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.ProjectGroupVersionKind),
 		managed.WithExternalConnecter(&connector{
-			kube:         mgr.GetClient(),
-			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: newBitbucketService}),
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(&keyAsExternalName{client: mgr.GetClient()}),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
@@ -94,12 +79,32 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
 
+// keyAsExternalName sets the external name of a Project to its configured key,
+// so that an existing project with that key is adopted rather than recreated.
+type keyAsExternalName struct {
+	client client.Client
+}
+
+func (i *keyAsExternalName) Initialize(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Project)
+	if !ok {
+		return errors.New(errNotProject)
+	}
+
+	if meta.GetExternalName(cr) != "" {
+		return nil
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Key)
+
+	return errors.Wrap(i.client.Update(ctx, cr), errUpdateCR)
+}
+
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
-	kube         client.Client
-	usage        resource.Tracker
-	newServiceFn func(baseURL string, creds []byte) (*BitbucketService, error)
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
 }
 
 // Connect typically produces an ExternalClient by:
@@ -117,30 +122,22 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errTrackPCUsage)
 	}
 
-	pc := &apisv1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
-	}
-
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
-	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
-	}
-
-	svc, err := c.newServiceFn(pc.Spec.BaseURL, data)
+	bc, err := c.newClientFn(ctx, c.kube, cr)
 	if err != nil {
 		return nil, errors.Wrap(err, errNewClient)
 	}
 
-	return &external{service: svc}, nil
+	return &external{kube: c.kube, projects: bc.Projects}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
-	// A 'client' used to connect to the external resource API.
-	service *BitbucketService
+	// kube is used to save the external name of projects whose key moved.
+	kube client.Client
+
+	// projects is used to manage projects through the bitbucket api.
+	projects bitbucket.ProjectService
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -155,9 +152,26 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		}, nil
 	}
 
-	p, err := c.service.Client.Projects.GetProject(ctx, &bitbucket.GetProjectRequest{
+	p, err := c.projects.GetProject(ctx, &bitbucket.GetProjectRequest{
 		Key: meta.GetExternalName(cr),
 	})
+	lateInitialized := false
+	if errors.Is(err, bitbucket.ErrNotFound) && cr.Spec.ForProvider.Key != meta.GetExternalName(cr) {
+		// A project whose key was moved without its new key being saved as
+		// the external name is found at the new key. It is only adopted when
+		// it is the project observed before, rather than another project
+		// that happens to use the key.
+		p, err = c.projects.GetProject(ctx, &bitbucket.GetProjectRequest{
+			Key: cr.Spec.ForProvider.Key,
+		})
+		if err == nil {
+			if cr.Status.AtProvider.ID == 0 || p.ID != cr.Status.AtProvider.ID {
+				return managed.ExternalObservation{}, errors.New(errKeyTaken)
+			}
+			meta.SetExternalName(cr, p.Key)
+			lateInitialized = true
+		}
+	}
 	if err != nil {
 		if errors.Is(err, bitbucket.ErrNotFound) {
 			log.Printf("Project with key (%s) does not exist\n", meta.GetExternalName(cr))
@@ -167,14 +181,6 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	cr.SetConditions(xpv1.Available())
-
-	if p.Description != cr.Spec.ForProvider.Description || p.Public != cr.Spec.ForProvider.Public {
-		return managed.ExternalObservation{
-			ResourceExists:   true,
-			ResourceUpToDate: false,
-		}, nil
-	}
-
 	cr.Status.AtProvider.ID = p.ID
 
 	return managed.ExternalObservation{
@@ -186,7 +192,11 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// Return false when the external resource exists, but it not up to date
 		// with the desired managed resource state. This lets the managed
 		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: true,
+		ResourceUpToDate: isUpToDate(cr, p),
+
+		// Return true when the external name was changed, so that it is
+		// saved.
+		ResourceLateInitialized: lateInitialized,
 
 		// Return any details that may be required to connect to the external
 		// resource. These will be stored as the connection secret.
@@ -205,13 +215,13 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	log.Printf("Attempting to create Project %s\n", cr.Name)
 
 	createReq := &bitbucket.CreateProjectRequest{
-		Name:        cr.Name,
+		Name:        cr.Spec.ForProvider.Name,
 		Key:         cr.Spec.ForProvider.Key,
 		Description: cr.Spec.ForProvider.Description,
 		Public:      cr.Spec.ForProvider.Public,
 	}
 
-	p, err := c.service.Client.Projects.CreateProject(ctx, createReq)
+	p, err := c.projects.CreateProject(ctx, createReq)
 	if err != nil {
 		log.Println(err)
 		return managed.ExternalCreation{}, err
@@ -230,13 +240,17 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	log.Printf("Attempting to update Project %s\n", cr.Name)
 
+	// the project is addressed by its current key, sending a different key
+	// in the body moves it
 	updateReq := &bitbucket.UpdateProjectRequest{
-		Key:         cr.Spec.ForProvider.Key,
+		Key:         meta.GetExternalName(cr),
+		NewKey:      cr.Spec.ForProvider.Key,
+		Name:        cr.Spec.ForProvider.Name,
 		Description: cr.Spec.ForProvider.Description,
 		Public:      cr.Spec.ForProvider.Public,
 	}
 
-	p, err := c.service.Client.Projects.UpdateProject(ctx, updateReq)
+	p, err := c.projects.UpdateProject(ctx, updateReq)
 	if err != nil {
 		log.Println(err)
 		return managed.ExternalUpdate{}, err
@@ -244,6 +258,16 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	log.Printf("Finished updating Project %+v\n", p)
 
+	// The managed reconciler only saves the status of the managed resource
+	// after an update, the new key has to be saved here for the project to
+	// be found at it.
+	if p.Key != meta.GetExternalName(cr) {
+		meta.SetExternalName(cr, p.Key)
+		if err := c.kube.Update(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCR)
+		}
+	}
+
 	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
 }
 
@@ -257,8 +281,8 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	cr.SetConditions(xpv1.Deleting())
 
-	err := c.service.Client.Projects.DeleteProject(ctx, &bitbucket.DeleteProjectRequest{
-		Key: cr.Spec.ForProvider.Key,
+	err := c.projects.DeleteProject(ctx, &bitbucket.DeleteProjectRequest{
+		Key: meta.GetExternalName(cr),
 	})
 	if err != nil {
 		log.Println(err)
@@ -269,3 +293,10 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	return nil
 }
+
+func isUpToDate(cr *v1alpha1.Project, p *bitbucket.Project) bool {
+	return p.Key == cr.Spec.ForProvider.Key &&
+		p.Name == cr.Spec.ForProvider.Name &&
+		p.Description == cr.Spec.ForProvider.Description &&
+		p.Public == cr.Spec.ForProvider.Public
+}
@@ -17,7 +17,29 @@ limitations under the License.
 package project
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -28,40 +50,396 @@ import (
 // https://github.com/golang/go/wiki/TestComments
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
+var (
+	errBoom = errors.New("boom")
+
+	scheme = func() *runtime.Scheme {
+		s := runtime.NewScheme()
+		_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+		return s
+	}()
+
+	key  = "PRJ"
+	name = "Team A"
+)
+
+type projectModifier func(*v1alpha1.Project)
+
+func withExternalName(n string) projectModifier {
+	return func(p *v1alpha1.Project) { meta.SetExternalName(p, n) }
+}
+
+func withKey(k string) projectModifier {
+	return func(p *v1alpha1.Project) { p.Spec.ForProvider.Key = k }
+}
+
+func withName(n string) projectModifier {
+	return func(p *v1alpha1.Project) { p.Spec.ForProvider.Name = n }
+}
+
+func withConditions(c ...xpv1.Condition) projectModifier {
+	return func(p *v1alpha1.Project) { p.Status.SetConditions(c...) }
+}
+
+func withID(id int) projectModifier {
+	return func(p *v1alpha1.Project) { p.Status.AtProvider.ID = id }
+}
+
+func project(m ...projectModifier) *v1alpha1.Project {
+	cr := &v1alpha1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a-prj"},
+		Spec: v1alpha1.ProjectSpec{
+			ForProvider: v1alpha1.ProjectParameters{
+				Key:  key,
+				Name: name,
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
 func TestObserve(t *testing.T) {
-	// type fields struct {
-	// 	service *BitbucketService
-	// }
-
-	// type args struct {
-	// 	ctx context.Context
-	// 	mg  resource.Managed
-	// }
-
-	// type want struct {
-	// 	o   managed.ExternalObservation
-	// 	err error
-	// }
-
-	// cases := map[string]struct {
-	// 	reason string
-	// 	fields fields
-	// 	args   args
-	// 	want   want
-	// }{
-	// 	// TODO: Add test cases.
-	// }
-
-	// for name, tc := range cases {
-	// 	t.Run(name, func(t *testing.T) {
-	// 		e := external{service: tc.fields.service}
-	// 		got, err := e.Observe(tc.args.ctx, tc.args.mg)
-	// 		if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-	// 			t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
-	// 		}
-	// 		if diff := cmp.Diff(tc.want.o, got); diff != "" {
-	// 			t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
-	// 		}
-	// 	})
-	// }
+	type fields struct {
+		projects bitbucket.ProjectService
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.Project
+		err error
+	}
+
+	observed := func(key, name string) func(context.Context, *bitbucket.GetProjectRequest) (*bitbucket.Project, error) {
+		return func(_ context.Context, req *bitbucket.GetProjectRequest) (*bitbucket.Project, error) {
+			if req.Key != key {
+				return nil, bitbucket.ErrNotFound
+			}
+			return &bitbucket.Project{ID: 1, Key: key, Name: name}, nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "We should report a project that does not exist.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed("OTHER", name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: project(withExternalName(key)),
+			},
+		},
+		"UpToDate": {
+			reason: "We should report a project that matches the desired state as up to date.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed(key, name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: project(withExternalName(key), withConditions(xpv1.Available()), withID(1)),
+			},
+		},
+		"NameDrift": {
+			reason: "We should report a project with a different name as not up to date.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed(key, "team-a-prj")}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: project(withExternalName(key), withConditions(xpv1.Available()), withID(1)),
+			},
+		},
+		"KeyChanged": {
+			reason: "We should observe the project at its current key and report a changed key as not up to date.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed(key, name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: project(withExternalName(key), withKey("NEW"), withConditions(xpv1.Available()), withID(1)),
+			},
+		},
+		"KeyMovedNotSaved": {
+			reason: "We should find a project whose key moved at its new key, and have the new external name saved.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed("NEW", name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"), withID(1))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+					ConnectionDetails:       managed.ConnectionDetails{},
+				},
+				cr: project(withExternalName("NEW"), withKey("NEW"), withConditions(xpv1.Available()), withID(1)),
+			},
+		},
+		"KeyTakenByOtherProject": {
+			reason: "We should not adopt another project that uses the new key.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed("NEW", name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"), withID(2))},
+			want: want{
+				cr:  project(withExternalName(key), withKey("NEW"), withID(2)),
+				err: errors.New(errKeyTaken),
+			},
+		},
+		"KeyTakenNeverObserved": {
+			reason: "We should not adopt a project at the new key when we cannot tell whether it is the project of this resource.",
+			fields: fields{projects: &fake.MockProjectService{MockGetProject: observed("NEW", name)}},
+			args:   args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"))},
+			want: want{
+				cr:  project(withExternalName(key), withKey("NEW")),
+				err: errors.New(errKeyTaken),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{projects: tc.fields.projects}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		cr  *v1alpha1.Project
+		err error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		projects bitbucket.ProjectService
+		args     args
+		want     want
+	}{
+		"UpdateFailed": {
+			reason: "We should return errors updating the project and keep its external name.",
+			projects: &fake.MockProjectService{
+				MockUpdateProject: func(context.Context, *bitbucket.UpdateProjectRequest) (*bitbucket.Project, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"))},
+			want: want{
+				cr:  project(withExternalName(key), withKey("NEW")),
+				err: errBoom,
+			},
+		},
+		"SaveKeyFailed": {
+			reason: "We should return errors saving the key the project moved to.",
+			projects: &fake.MockProjectService{
+				MockUpdateProject: func(context.Context, *bitbucket.UpdateProjectRequest) (*bitbucket.Project, error) {
+					return &bitbucket.Project{Key: "NEW", Name: name}, nil
+				},
+			},
+			// the managed resource was not saved, so it cannot be updated
+			args: args{ctx: context.Background(), mg: project(withExternalName(key), withKey("NEW"))},
+			want: want{
+				cr:  project(withExternalName("NEW"), withKey("NEW")),
+				err: errors.Wrap(errors.New(`projects.project.bitbucketserver.crossplane.io "team-a-prj" not found`), errUpdateCR),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{kube: kubefake.NewClientBuilder().WithScheme(scheme).Build(), projects: tc.projects}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// server is a fake of the projects of a bitbucket server, keyed by key.
+type server map[string]bitbucket.Project
+
+func (srv server) service() *fake.MockProjectService {
+	return &fake.MockProjectService{
+		MockGetProject: func(_ context.Context, req *bitbucket.GetProjectRequest) (*bitbucket.Project, error) {
+			p, ok := srv[req.Key]
+			if !ok {
+				return nil, bitbucket.ErrNotFound
+			}
+			return &p, nil
+		},
+		MockUpdateProject: func(_ context.Context, req *bitbucket.UpdateProjectRequest) (*bitbucket.Project, error) {
+			p, ok := srv[req.Key]
+			if !ok {
+				return nil, bitbucket.ErrNotFound
+			}
+			delete(srv, req.Key)
+			p.Key, p.Name, p.Description, p.Public = req.NewKey, req.Name, req.Description, req.Public
+			srv[p.Key] = p
+			return &p, nil
+		},
+	}
+}
+
+func TestUpdateThenObserve(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		server server
+		cr     *v1alpha1.Project
+		want   server
+	}{
+		"Rename": {
+			reason: "We should rename the project, and find it up to date afterwards.",
+			server: server{key: {ID: 1, Key: key, Name: "team-a-prj"}},
+			cr:     project(withExternalName(key)),
+			want:   server{key: {ID: 1, Key: key, Name: name}},
+		},
+		"MoveKey": {
+			reason: "We should move the project to its new key, and find it there afterwards rather than recreating it.",
+			server: server{key: {ID: 1, Key: key, Name: name}},
+			cr:     project(withExternalName(key), withKey("NEW")),
+			want:   server{"NEW": {ID: 1, Key: "NEW", Name: name}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			kube := kubefake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.cr).Build()
+			e := external{kube: kube, projects: tc.server.service()}
+
+			o, err := e.Observe(ctx, tc.cr)
+			if err != nil || !o.ResourceExists || o.ResourceUpToDate {
+				t.Fatalf("\n%s\ne.Observe(...): want existing project that is not up to date, got %+v, %v\n", tc.reason, o, err)
+			}
+			if _, err := e.Update(ctx, tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): unexpected error: %s\n", tc.reason, err)
+			}
+
+			// The managed reconciler observes the resource as it was saved.
+			saved := &v1alpha1.Project{}
+			if err := kube.Get(ctx, types.NamespacedName{Name: tc.cr.GetName()}, saved); err != nil {
+				t.Fatal(err)
+			}
+			o, err = e.Observe(ctx, saved)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): unexpected error: %s\n", tc.reason, err)
+			}
+			want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: managed.ConnectionDetails{}}
+			if diff := cmp.Diff(want, o); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, tc.server); diff != "" {
+				t.Errorf("\n%s\nserver: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = apisv1alpha1.SchemeBuilder.AddToScheme(s)
+
+	type want struct {
+		err error
+		// is is the sentinel the error matches, for errors whose message
+		// depends on the server
+		is error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		baseURL string
+		want    want
+	}{
+		"BadURL": {
+			reason:  "We should return an error, rather than exit, when the base URL of the ProviderConfig cannot be parsed.",
+			baseURL: "http://bitbucket example.org",
+			want:    want{err: errors.Wrap(errors.Wrap(errors.New(`parse "http://bitbucket example.org": invalid character " " in host name`), "cannot create bitbucket client"), errNewClient)},
+		},
+		"BadCredentials": {
+			reason:  "We should return an error, rather than exit, when the server rejects the credentials of the ProviderConfig.",
+			baseURL: srv.URL,
+			want:    want{is: bitbucket.ErrPermission},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "bitbucket", UID: types.UID(t.Name())},
+				Spec: apisv1alpha1.ProviderConfigSpec{
+					BaseURL: tc.baseURL,
+					Credentials: apisv1alpha1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{
+							SecretReference: xpv1.SecretReference{Name: "bitbucket-creds", Namespace: "crossplane-system"},
+							Key:             "credentials",
+						}},
+					},
+				},
+			}
+			sec := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bitbucket-creds", Namespace: "crossplane-system"},
+				Data:       map[string][]byte{"credentials": []byte(`{"username":"admin","password":"wrong"}`)},
+			}
+			cr := project()
+			cr.Spec.ProviderConfigReference = &xpv1.Reference{Name: "bitbucket"}
+
+			c := &connector{
+				kube:        kubefake.NewClientBuilder().WithScheme(s).WithObjects(pc, sec).Build(),
+				usage:       resource.TrackerFn(func(context.Context, resource.Managed) error { return nil }),
+				newClientFn: clients.GetClient,
+			}
+			_, err := c.Connect(context.Background(), cr)
+			if tc.want.is != nil {
+				if !errors.Is(err, tc.want.is) {
+					t.Errorf("\n%s\nc.Connect(...): want error matching %v, got %v", tc.reason, tc.want.is, err)
+				}
+			} else if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
 }
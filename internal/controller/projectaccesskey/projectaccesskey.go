@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectaccesskey
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/sshaccess"
+)
+
+// scope tells the access key controller which access a ProjectAccessKey grants.
+var scope = sshaccess.Scope[*v1alpha1.ProjectAccessKey]{
+	Kind: v1alpha1.ProjectAccessKeyKind,
+	Key: func(cr *v1alpha1.ProjectAccessKey) sshaccess.Key {
+		return sshaccess.Key{
+			ProjectKey:         cr.Spec.ForProvider.ProjectKey,
+			PublicKeySecretRef: cr.Spec.ForProvider.PublicKeySecretRef,
+			Label:              cr.Spec.ForProvider.Label,
+			Permission:         cr.Spec.ForProvider.Permission,
+		}
+	},
+	Observe: func(cr *v1alpha1.ProjectAccessKey, id int, permission string) {
+		cr.Status.AtProvider = v1alpha1.ProjectAccessKeyObservation{ID: id, Permission: permission}
+	},
+}
+
+// Setup adds a controller that reconciles ProjectAccessKey managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return sshaccess.Setup(mgr, o, v1alpha1.ProjectAccessKeyGroupVersionKind, &v1alpha1.ProjectAccessKey{}, scope)
+}
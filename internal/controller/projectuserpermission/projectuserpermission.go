@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectuserpermission
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/project/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/permission"
+)
+
+// scope tells the permission controller which permission a ProjectUserPermission grants.
+var scope = permission.Scope[*v1alpha1.ProjectUserPermission]{
+	Kind:        v1alpha1.ProjectUserPermissionKind,
+	Description: "project user permission",
+	Grant: func(cr *v1alpha1.ProjectUserPermission) permission.Grant {
+		return permission.Grant{
+			ProjectKey: cr.Spec.ForProvider.ProjectKey,
+			User:       cr.Spec.ForProvider.User,
+			Permission: cr.Spec.ForProvider.Permission,
+		}
+	},
+	Observe: func(cr *v1alpha1.ProjectUserPermission, perm string) {
+		cr.Status.AtProvider.Permission = perm
+	},
+}
+
+// Setup adds a controller that reconciles ProjectUserPermission managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return permission.Setup(mgr, o, v1alpha1.ProjectUserPermissionGroupVersionKind, &v1alpha1.ProjectUserPermission{}, scope)
+}
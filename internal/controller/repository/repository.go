@@ -0,0 +1,331 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+)
+
+const (
+	errNotRepository = "managed resource is not a Repository custom resource"
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errUpdateCR      = "cannot update Repository custom resource"
+
+	errNewClient = "cannot create new Service"
+
+	errGetRepository    = "cannot get Bitbucket repository"
+	errGetDefaultBranch = "cannot get default branch of Bitbucket repository"
+	errCreateRepository = "cannot create Bitbucket repository"
+	errUpdateRepository = "cannot update Bitbucket repository"
+	errSetDefaultBranch = "cannot set default branch of Bitbucket repository"
+	errDeleteRepository = "cannot delete Bitbucket repository"
+)
+
+const (
+	branchRefPrefix = "refs/heads/"
+
+	// defaultForkable matches the default bitbucket applies to new repositories.
+	defaultForkable = true
+)
+
+// Setup adds a controller that reconciles Repository managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.RepositoryGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.RepositoryGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(&slugAsExternalName{client: mgr.GetClient()}),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Repository{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// slugAsExternalName sets the external name of a Repository that adopts an
+// existing repository to its configured slug. Repositories without a slug are
+// created, and named after the slug bitbucket derives from their name, so
+// that an unrelated repository is never adopted by accident.
+type slugAsExternalName struct {
+	client client.Client
+}
+
+func (i *slugAsExternalName) Initialize(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return errors.New(errNotRepository)
+	}
+
+	if meta.GetExternalName(cr) != "" || cr.Spec.ForProvider.Slug == "" {
+		return nil
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Slug)
+
+	return errors.Wrap(i.client.Update(ctx, cr), errUpdateCR)
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return nil, errors.New(errNotRepository)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external{kube: c.kube, repositories: bc.Repositories}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external struct {
+	// kube is used to save the external name of renamed repositories.
+	kube client.Client
+
+	// repositories is used to manage repositories through the bitbucket api.
+	repositories bitbucket.RepositoryService
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotRepository)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	r, err := c.repositories.GetRepository(ctx, &bitbucket.GetRepositoryRequest{
+		ProjectKey: cr.Spec.ForProvider.ProjectKey,
+		Slug:       meta.GetExternalName(cr),
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetRepository)
+	}
+
+	defaultBranch := ""
+	if cr.Spec.ForProvider.DefaultBranch != "" {
+		b, err := c.repositories.GetDefaultBranch(ctx, &bitbucket.GetDefaultBranchRequest{
+			ProjectKey: cr.Spec.ForProvider.ProjectKey,
+			Slug:       r.Slug,
+		})
+		// empty repositories may not report a default branch yet
+		if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetDefaultBranch)
+		}
+		if err == nil {
+			defaultBranch = b.DisplayID
+		}
+	}
+
+	cr.Status.AtProvider = generateObservation(r)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  isUpToDate(cr.Spec.ForProvider, r, defaultBranch),
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotRepository)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	p := cr.Spec.ForProvider
+	r, err := c.repositories.CreateRepository(ctx, &bitbucket.CreateRepositoryRequest{
+		ProjectKey:    p.ProjectKey,
+		Name:          p.Name,
+		Description:   p.Description,
+		Forkable:      forkable(p),
+		Public:        p.Public,
+		DefaultBranch: p.DefaultBranch,
+	})
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRepository)
+	}
+
+	meta.SetExternalName(cr, r.Slug)
+
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotRepository)
+	}
+
+	p := cr.Spec.ForProvider
+	r, err := c.repositories.UpdateRepository(ctx, &bitbucket.UpdateRepositoryRequest{
+		ProjectKey:  p.ProjectKey,
+		Slug:        meta.GetExternalName(cr),
+		Name:        p.Name,
+		Description: p.Description,
+		Forkable:    forkable(p),
+		Public:      p.Public,
+	})
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRepository)
+	}
+
+	// Renaming a repository changes its slug. The managed reconciler only
+	// saves the status of the managed resource after an update, so the new
+	// slug has to be saved here for the repository to be found at it.
+	if r.Slug != meta.GetExternalName(cr) {
+		meta.SetExternalName(cr, r.Slug)
+		if err := c.kube.Update(ctx, cr); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCR)
+		}
+	}
+
+	if p.DefaultBranch != "" {
+		err = c.repositories.SetDefaultBranch(ctx, &bitbucket.SetDefaultBranchRequest{
+			ProjectKey: p.ProjectKey,
+			Slug:       r.Slug,
+			ID:         branchRef(p.DefaultBranch),
+		})
+		if err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errSetDefaultBranch)
+		}
+	}
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return errors.New(errNotRepository)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	err := c.repositories.DeleteRepository(ctx, &bitbucket.DeleteRepositoryRequest{
+		ProjectKey: cr.Spec.ForProvider.ProjectKey,
+		Slug:       meta.GetExternalName(cr),
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteRepository)
+	}
+
+	return nil
+}
+
+func generateObservation(r *bitbucket.Repository) v1alpha1.RepositoryObservation {
+	o := v1alpha1.RepositoryObservation{
+		ID:    r.ID,
+		Slug:  r.Slug,
+		State: r.State,
+	}
+	for _, l := range r.Links.Clone {
+		switch l.Name {
+		case "http", "https":
+			o.HTTPCloneURL = l.Href
+		case "ssh":
+			o.SSHCloneURL = l.Href
+		}
+	}
+	return o
+}
+
+func isUpToDate(p v1alpha1.RepositoryParameters, r *bitbucket.Repository, defaultBranch string) bool {
+	switch {
+	case p.Name != r.Name,
+		p.Description != r.Description,
+		forkable(p) != r.Forkable,
+		p.Public != r.Public:
+		return false
+	case p.DefaultBranch != "" && strings.TrimPrefix(p.DefaultBranch, branchRefPrefix) != defaultBranch:
+		return false
+	}
+	return true
+}
+
+func forkable(p v1alpha1.RepositoryParameters) bool {
+	if p.Forkable == nil {
+		return defaultForkable
+	}
+	return *p.Forkable
+}
+
+func branchRef(branch string) string {
+	if strings.HasPrefix(branch, branchRefPrefix) {
+		return branch
+	}
+	return branchRefPrefix + branch
+}
@@ -0,0 +1,434 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	scheme = func() *runtime.Scheme {
+		s := runtime.NewScheme()
+		_ = v1alpha1.SchemeBuilder.AddToScheme(s)
+		return s
+	}()
+
+	projectKey = "PRJ"
+	slug       = "my-repo"
+	name       = "My Repo"
+)
+
+type repositoryModifier func(*v1alpha1.Repository)
+
+func withExternalName(n string) repositoryModifier {
+	return func(r *v1alpha1.Repository) { meta.SetExternalName(r, n) }
+}
+
+func withSlug(sl string) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Spec.ForProvider.Slug = sl }
+}
+
+func withName(n string) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Spec.ForProvider.Name = n }
+}
+
+func withDescription(d string) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Spec.ForProvider.Description = d }
+}
+
+func withDefaultBranch(b string) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Spec.ForProvider.DefaultBranch = b }
+}
+
+func withConditions(c ...xpv1.Condition) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Status.SetConditions(c...) }
+}
+
+func withObservation(o v1alpha1.RepositoryObservation) repositoryModifier {
+	return func(r *v1alpha1.Repository) { r.Status.AtProvider = o }
+}
+
+func repository(m ...repositoryModifier) *v1alpha1.Repository {
+	cr := &v1alpha1.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-repo"},
+		Spec: v1alpha1.RepositorySpec{
+			ForProvider: v1alpha1.RepositoryParameters{
+				ProjectKey: projectKey,
+				Name:       name,
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type fields struct {
+		repositories bitbucket.RepositoryService
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.Repository
+		err error
+	}
+
+	observed := &bitbucket.Repository{
+		ID:       1,
+		Slug:     slug,
+		Name:     name,
+		State:    "AVAILABLE",
+		Forkable: true,
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "We should report a repository that does not exist.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: repository(withExternalName(slug)),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the repository.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					return nil, errBoom
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug))},
+			want: want{
+				cr:  repository(withExternalName(slug)),
+				err: errors.Wrap(errBoom, errGetRepository),
+			},
+		},
+		"UpToDate": {
+			reason: "We should report a repository that matches the desired state as up to date.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(_ context.Context, req *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					if req.ProjectKey != projectKey || req.Slug != slug {
+						return nil, bitbucket.ErrNotFound
+					}
+					return observed, nil
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: repository(
+					withExternalName(slug),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.RepositoryObservation{ID: 1, Slug: slug, State: "AVAILABLE"}),
+				),
+			},
+		},
+		"DescriptionDrift": {
+			reason: "We should report a repository with a different description as not up to date.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					return observed, nil
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug), withDescription("new"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: repository(
+					withExternalName(slug),
+					withDescription("new"),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.RepositoryObservation{ID: 1, Slug: slug, State: "AVAILABLE"}),
+				),
+			},
+		},
+		"DefaultBranchDrift": {
+			reason: "We should report a repository with a different default branch as not up to date.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					return observed, nil
+				},
+				MockGetDefaultBranch: func(context.Context, *bitbucket.GetDefaultBranchRequest) (*bitbucket.Branch, error) {
+					return &bitbucket.Branch{ID: "refs/heads/master", DisplayID: "master"}, nil
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug), withDefaultBranch("main"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: repository(
+					withExternalName(slug),
+					withDefaultBranch("main"),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.RepositoryObservation{ID: 1, Slug: slug, State: "AVAILABLE"}),
+				),
+			},
+		},
+		"DefaultBranchRef": {
+			reason: "We should compare a default branch given as a ref by its name, rather than update it on every poll.",
+			fields: fields{repositories: &fake.MockRepositoryService{
+				MockGetRepository: func(context.Context, *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+					return observed, nil
+				},
+				MockGetDefaultBranch: func(context.Context, *bitbucket.GetDefaultBranchRequest) (*bitbucket.Branch, error) {
+					return &bitbucket.Branch{ID: "refs/heads/main", DisplayID: "main"}, nil
+				},
+			}},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug), withDefaultBranch("refs/heads/main"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: repository(
+					withExternalName(slug),
+					withDefaultBranch("refs/heads/main"),
+					withConditions(xpv1.Available()),
+					withObservation(v1alpha1.RepositoryObservation{ID: 1, Slug: slug, State: "AVAILABLE"}),
+				),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{repositories: tc.fields.repositories}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		cr  *v1alpha1.Repository
+		err error
+	}
+
+	cases := map[string]struct {
+		reason       string
+		repositories bitbucket.RepositoryService
+		args         args
+		want         want
+	}{
+		"SetDefaultBranch": {
+			reason: "We should set the default branch using its fully qualified ref.",
+			repositories: &fake.MockRepositoryService{
+				MockUpdateRepository: func(context.Context, *bitbucket.UpdateRepositoryRequest) (*bitbucket.Repository, error) {
+					return &bitbucket.Repository{Slug: slug, Name: name}, nil
+				},
+				MockSetDefaultBranch: func(_ context.Context, req *bitbucket.SetDefaultBranchRequest) error {
+					if req.ID != "refs/heads/main" {
+						return errBoom
+					}
+					return nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug), withDefaultBranch("main"))},
+			want: want{cr: repository(withExternalName(slug), withDefaultBranch("main"))},
+		},
+		"UpdateFailed": {
+			reason: "We should return errors updating the repository.",
+			repositories: &fake.MockRepositoryService{
+				MockUpdateRepository: func(context.Context, *bitbucket.UpdateRepositoryRequest) (*bitbucket.Repository, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: repository(withExternalName(slug))},
+			want: want{
+				cr:  repository(withExternalName(slug)),
+				err: errors.Wrap(errBoom, errUpdateRepository),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{kube: kubefake.NewClientBuilder().WithScheme(scheme).Build(), repositories: tc.repositories}
+			_, err := e.Update(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Repository
+		want   *v1alpha1.Repository
+	}{
+		"Slug": {
+			reason: "We should adopt the repository with the configured slug.",
+			cr:     repository(withSlug("existing")),
+			want:   repository(withSlug("existing"), withExternalName("existing")),
+		},
+		"NoSlug": {
+			reason: "We should not adopt a repository named after the managed resource when no slug is configured.",
+			cr:     repository(),
+			want:   repository(),
+		},
+		"ExternalNameSet": {
+			reason: "We should keep the external name of a repository that was already created or adopted.",
+			cr:     repository(withSlug("existing"), withExternalName(slug)),
+			want:   repository(withSlug("existing"), withExternalName(slug)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := kubefake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.cr).Build()
+			i := &slugAsExternalName{client: kube}
+			if err := i.Initialize(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ni.Initialize(...): unexpected error: %s\n", tc.reason, err)
+			}
+			got := &v1alpha1.Repository{}
+			if err := kube.Get(context.Background(), types.NamespacedName{Name: tc.cr.GetName()}, got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(meta.GetExternalName(tc.want), meta.GetExternalName(got)); diff != "" {
+				t.Errorf("\n%s\ni.Initialize(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenameThenObserve(t *testing.T) {
+	ctx := context.Background()
+
+	// repos holds the repositories of the project, keyed by slug
+	repos := map[string]bitbucket.Repository{
+		"old-slug": {ID: 1, Slug: "old-slug", Name: "Old Name", Forkable: true},
+	}
+	repositories := &fake.MockRepositoryService{
+		MockGetRepository: func(_ context.Context, req *bitbucket.GetRepositoryRequest) (*bitbucket.Repository, error) {
+			r, ok := repos[req.Slug]
+			if !ok {
+				return nil, bitbucket.ErrNotFound
+			}
+			return &r, nil
+		},
+		MockUpdateRepository: func(_ context.Context, req *bitbucket.UpdateRepositoryRequest) (*bitbucket.Repository, error) {
+			r, ok := repos[req.Slug]
+			if !ok {
+				return nil, bitbucket.ErrNotFound
+			}
+			delete(repos, req.Slug)
+			r.Name, r.Slug = req.Name, slug
+			repos[r.Slug] = r
+			return &r, nil
+		},
+	}
+
+	cr := repository(withName(name), withExternalName("old-slug"))
+	kube := kubefake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build()
+	e := external{kube: kube, repositories: repositories}
+
+	o, err := e.Observe(ctx, cr)
+	if err != nil || !o.ResourceExists || o.ResourceUpToDate {
+		t.Fatalf("e.Observe(...): want existing repository that is not up to date, got %+v, %v", o, err)
+	}
+	if _, err := e.Update(ctx, cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %s", err)
+	}
+
+	// The managed reconciler observes the repository as it was saved.
+	saved := &v1alpha1.Repository{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: cr.GetName()}, saved); err != nil {
+		t.Fatal(err)
+	}
+	o, err = e.Observe(ctx, saved)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %s", err)
+	}
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: managed.ConnectionDetails{}}
+	if diff := cmp.Diff(want, o); diff != "" {
+		t.Errorf("We should find the renamed repository at its new slug.\ne.Observe(...): -want, +got:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repositorygrouppermission
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/permission"
+)
+
+// The reconciliation logic is shared by all permission kinds and tested by the
+// permission package, so only what is specific to this kind is tested here.
+
+func TestScope(t *testing.T) {
+	cr := &v1alpha1.RepositoryGroupPermission{
+		Spec: v1alpha1.RepositoryGroupPermissionSpec{
+			ForProvider: v1alpha1.RepositoryGroupPermissionParameters{
+				ProjectKey:     "PRJ",
+				RepositorySlug: "repo",
+				Group:          "devs",
+				Permission:     "REPO_WRITE",
+			},
+		},
+	}
+
+	want := permission.Grant{
+		ProjectKey:     "PRJ",
+		RepositorySlug: "repo",
+		Group:          "devs",
+		Permission:     "REPO_WRITE",
+	}
+	if diff := cmp.Diff(want, scope.Grant(cr)); diff != "" {
+		t.Errorf("scope.Grant(...): -want, +got:\n%s\n", diff)
+	}
+
+	scope.Observe(cr, "REPO_WRITE")
+	if diff := cmp.Diff("REPO_WRITE", cr.Status.AtProvider.Permission); diff != "" {
+		t.Errorf("scope.Observe(...): -want, +got:\n%s\n", diff)
+	}
+}
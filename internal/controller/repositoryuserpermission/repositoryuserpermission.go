@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repositoryuserpermission
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/repository/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/permission"
+)
+
+// scope tells the permission controller which permission a RepositoryUserPermission grants.
+var scope = permission.Scope[*v1alpha1.RepositoryUserPermission]{
+	Kind:        v1alpha1.RepositoryUserPermissionKind,
+	Description: "repository user permission",
+	Grant: func(cr *v1alpha1.RepositoryUserPermission) permission.Grant {
+		return permission.Grant{
+			ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+			RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+			User:           cr.Spec.ForProvider.User,
+			Permission:     cr.Spec.ForProvider.Permission,
+		}
+	},
+	Observe: func(cr *v1alpha1.RepositoryUserPermission, perm string) {
+		cr.Status.AtProvider.Permission = perm
+	},
+}
+
+// Setup adds a controller that reconciles RepositoryUserPermission managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return permission.Setup(mgr, o, v1alpha1.RepositoryUserPermissionGroupVersionKind, &v1alpha1.RepositoryUserPermission{}, scope)
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sshaccess reconciles the access keys that grant SSH keys access to
+// projects and repositories. The managed resources of each scope share this
+// controller, and only tell it which access they grant.
+package sshaccess
+
+import (
+	"context"
+	"strconv"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/sshkey"
+)
+
+const (
+	errNotKind      = "managed resource is not a custom resource of kind %s"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+
+	errNewClient = "cannot create new Service"
+
+	errInvalidID        = "external name is not a valid access key ID"
+	errGetPublicKey     = "cannot get public key"
+	errNoPublicKey      = "referenced secret key holds no public key"
+	errGenerateKeyPair  = "cannot generate keypair"
+	errGetAccessKey     = "cannot get Bitbucket access key"
+	errCreateAccessKey  = "cannot create Bitbucket access key"
+	errUpdatePermission = "cannot update permission of Bitbucket access key"
+	errDeleteAccessKey  = "cannot delete Bitbucket access key"
+)
+
+// A Key is the access an SSH key is granted to a project, or to a repository
+// of a project.
+type Key struct {
+	ProjectKey string
+
+	// RepositorySlug is the repository the key grants access to, it is empty
+	// for keys granting access to the project itself.
+	RepositorySlug string
+
+	// PublicKeySecretRef references the public key, a keypair is generated
+	// when it is nil.
+	PublicKeySecretRef *xpv1.SecretKeySelector
+
+	// Label of the key, empty to use the name of the managed resource.
+	Label string
+
+	// Permission granted to the key, e.g. REPO_READ.
+	Permission string
+}
+
+// A Scope tells the controller about the access managed resources of type T
+// grant.
+type Scope[T resource.Managed] struct {
+	// Kind of the managed resources.
+	Kind string
+
+	// Key returns the access the managed resource grants.
+	Key func(cr T) Key
+
+	// Observe records the ID and permission of the key in the status of the
+	// managed resource.
+	Observe func(cr T, id int, permission string)
+}
+
+// Setup adds a controller that reconciles the access key managed resources of
+// type T, of which obj is an instance.
+func Setup[T resource.Managed](mgr ctrl.Manager, o controller.Options, gvk schema.GroupVersionKind, obj T, s Scope[T]) error {
+	name := managed.ControllerName(gvk.GroupKind().String())
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(gvk),
+		managed.WithExternalConnecter(&connector[T]{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient,
+			scope:       s}),
+		managed.WithInitializers(),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(obj).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector[T resource.Managed] struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+	scope       Scope[T]
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector[T]) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return nil, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	return &external[T]{kube: c.kube, keys: bc.AccessKeys, newKeyPair: sshkey.GenerateKeyPair, scope: c.scope}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external[T resource.Managed] struct {
+	// kube is used to read the public key of the access key.
+	kube client.Client
+	// keys is used to manage access keys through the bitbucket api.
+	keys bitbucket.AccessKeyService
+	// newKeyPair generates a keypair when no public key is referenced.
+	newKeyPair func() (publicKey []byte, privateKey []byte, err error)
+
+	scope Scope[T]
+}
+
+func (c *external[T]) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalObservation{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errInvalidID)
+	}
+
+	key := c.scope.Key(cr)
+	k, err := c.keys.GetAccessKey(ctx, &bitbucket.GetAccessKeyRequest{
+		ProjectKey:     key.ProjectKey,
+		RepositorySlug: key.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAccessKey)
+	}
+
+	c.scope.Observe(cr, k.Key.ID, k.Permission)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  k.Permission == key.Permission,
+		ConnectionDetails: managed.ConnectionDetails{sshkey.PublicKey: []byte(k.Key.Text)},
+	}, nil
+}
+
+func (c *external[T]) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalCreation{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	key := c.scope.Key(cr)
+	conn := managed.ConnectionDetails{}
+	publicKey, err := c.publicKey(ctx, key.PublicKeySecretRef)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if publicKey == nil {
+		var privateKey []byte
+		publicKey, privateKey, err = c.newKeyPair()
+		if err != nil {
+			return managed.ExternalCreation{}, errors.Wrap(err, errGenerateKeyPair)
+		}
+		conn[sshkey.PrivateKey] = privateKey
+	}
+	conn[sshkey.PublicKey] = publicKey
+
+	label := key.Label
+	if label == "" {
+		label = cr.GetName()
+	}
+
+	k, err := c.keys.CreateAccessKey(ctx, &bitbucket.CreateAccessKeyRequest{
+		ProjectKey:     key.ProjectKey,
+		RepositorySlug: key.RepositorySlug,
+		Key:            bitbucket.SSHKey{Text: string(publicKey), Label: label},
+		Permission:     key.Permission,
+	})
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateAccessKey)
+	}
+
+	meta.SetExternalName(cr, strconv.Itoa(k.Key.ID))
+
+	return managed.ExternalCreation{ConnectionDetails: conn}, nil
+}
+
+func (c *external[T]) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(T)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvalidID)
+	}
+
+	key := c.scope.Key(cr)
+	_, err = c.keys.UpdateAccessKeyPermission(ctx, &bitbucket.UpdateAccessKeyPermissionRequest{
+		ProjectKey:     key.ProjectKey,
+		RepositorySlug: key.RepositorySlug,
+		ID:             id,
+		Permission:     key.Permission,
+	})
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrap(err, errUpdatePermission)
+}
+
+func (c *external[T]) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(T)
+	if !ok {
+		return errors.Errorf(errNotKind, c.scope.Kind)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return errors.Wrap(err, errInvalidID)
+	}
+
+	key := c.scope.Key(cr)
+	err = c.keys.DeleteAccessKey(ctx, &bitbucket.DeleteAccessKeyRequest{
+		ProjectKey:     key.ProjectKey,
+		RepositorySlug: key.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteAccessKey)
+	}
+
+	return nil
+}
+
+// publicKey reads the referenced public key, which is nil when no public key
+// is referenced.
+func (c *external[T]) publicKey(ctx context.Context, ref *xpv1.SecretKeySelector) ([]byte, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetPublicKey)
+	}
+	if len(s.Data[ref.Key]) == 0 {
+		return nil, errors.New(errNoPublicKey)
+	}
+	return s.Data[ref.Key], nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strconv"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/tomas-mota/provider-bitbucketserver/apis/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/apis/webhook/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/clients"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/compare"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/controller/features"
+)
+
+const (
+	errNotWebhook   = "managed resource is not a Webhook custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+
+	errNewClient       = "cannot create new Service"
+	errGetCapabilities = "cannot get capabilities of the server"
+
+	errInvalidID     = "external name is not a valid webhook ID"
+	errGetSecret     = "cannot get webhook secret"
+	errGetWebhook    = "cannot get Bitbucket webhook"
+	errCreateWebhook = "cannot create Bitbucket webhook"
+	errUpdateWebhook = "cannot update Bitbucket webhook"
+	errDeleteWebhook = "cannot delete Bitbucket webhook"
+)
+
+// Setup adds a controller that reconciles Webhook managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha1.WebhookGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.WebhookGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: clients.GetClient}),
+		managed.WithInitializers(),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Webhook{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*bitbucket.Client, error)
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return nil, errors.New(errNotWebhook)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	bc, err := c.newClientFn(ctx, c.kube, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+
+	if cr.Spec.ForProvider.RepositorySlug == "" {
+		caps, err := bc.Capabilities(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetCapabilities)
+		}
+		if err := caps.Require(bitbucket.FeatureProjectWebhooks); err != nil {
+			return clients.Unsupported(err), nil
+		}
+	}
+
+	return &external{kube: c.kube, webhooks: bc.Webhooks}, nil
+}
+
+// An ExternalClient observes, then either creates, updates, or deletes an
+// external resource to ensure it reflects the managed resource's desired state.
+type external struct {
+	// kube is used to read the secret of the webhook.
+	kube client.Client
+	// webhooks is used to manage webhooks through the bitbucket api.
+	webhooks bitbucket.WebhookService
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotWebhook)
+	}
+
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errInvalidID)
+	}
+
+	w, err := c.webhooks.GetWebhook(ctx, &bitbucket.GetWebhookRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil {
+		if errors.Is(err, bitbucket.ErrNotFound) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetWebhook)
+	}
+
+	cr.Status.AtProvider.ID = w.ID
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  isUpToDate(cr, w),
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotWebhook)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+
+	secret, err := c.secret(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	w, err := c.webhooks.CreateWebhook(ctx, &bitbucket.CreateWebhookRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		Name:           webhookName(cr),
+		URL:            cr.Spec.ForProvider.URL,
+		Events:         cr.Spec.ForProvider.Events,
+		Active:         active(cr),
+		Configuration:  bitbucket.WebhookConfiguration{Secret: secret},
+	})
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateWebhook)
+	}
+
+	meta.SetExternalName(cr, strconv.Itoa(w.ID))
+
+	return managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotWebhook)
+	}
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvalidID)
+	}
+
+	secret, err := c.secret(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	_, err = c.webhooks.UpdateWebhook(ctx, &bitbucket.UpdateWebhookRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+		Name:           webhookName(cr),
+		URL:            cr.Spec.ForProvider.URL,
+		Events:         cr.Spec.ForProvider.Events,
+		Active:         active(cr),
+		Configuration:  bitbucket.WebhookConfiguration{Secret: secret},
+	})
+
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, errors.Wrap(err, errUpdateWebhook)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return errors.New(errNotWebhook)
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+
+	id, err := strconv.Atoi(meta.GetExternalName(cr))
+	if err != nil {
+		return errors.Wrap(err, errInvalidID)
+	}
+
+	err = c.webhooks.DeleteWebhook(ctx, &bitbucket.DeleteWebhookRequest{
+		ProjectKey:     cr.Spec.ForProvider.ProjectKey,
+		RepositorySlug: cr.Spec.ForProvider.RepositorySlug,
+		ID:             id,
+	})
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteWebhook)
+	}
+
+	return nil
+}
+
+// secret reads the secret used to sign the payloads of the webhook, which is
+// empty when no secret is referenced.
+func (c *external) secret(ctx context.Context, cr *v1alpha1.Webhook) (string, error) {
+	ref := cr.Spec.ForProvider.SecretRef
+	if ref == nil {
+		return "", nil
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", errors.Wrap(err, errGetSecret)
+	}
+	return string(s.Data[ref.Key]), nil
+}
+
+// webhookName returns the configured name of the webhook, falling back to the
+// name of the managed resource.
+func webhookName(cr *v1alpha1.Webhook) string {
+	if cr.Spec.ForProvider.Name != "" {
+		return cr.Spec.ForProvider.Name
+	}
+	return cr.GetName()
+}
+
+func active(cr *v1alpha1.Webhook) bool {
+	if cr.Spec.ForProvider.Active == nil {
+		return true
+	}
+	return *cr.Spec.ForProvider.Active
+}
+
+// isUpToDate compares the observable fields of the webhook. The secret is
+// never returned by bitbucket, so changes to it can not be detected.
+func isUpToDate(cr *v1alpha1.Webhook, w *bitbucket.Webhook) bool {
+	if webhookName(cr) != w.Name || cr.Spec.ForProvider.URL != w.URL || active(cr) != w.Active {
+		return false
+	}
+
+	return compare.SameSet(cr.Spec.ForProvider.Events, w.Events)
+}
@@ -0,0 +1,514 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	kubefake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tomas-mota/provider-bitbucketserver/apis/webhook/v1alpha1"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket/fake"
+	"github.com/tomas-mota/provider-bitbucketserver/internal/test"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+var (
+	errBoom = errors.New("boom")
+
+	projectKey = "PRJ"
+	slug       = "my-repo"
+	name       = "ci"
+	hookURL    = "https://ci.example.com/hook"
+)
+
+type webhookModifier func(*v1alpha1.Webhook)
+
+func withExternalName(n string) webhookModifier {
+	return func(w *v1alpha1.Webhook) { meta.SetExternalName(w, n) }
+}
+
+func withURL(u string) webhookModifier {
+	return func(w *v1alpha1.Webhook) { w.Spec.ForProvider.URL = u }
+}
+
+func withEvents(e ...string) webhookModifier {
+	return func(w *v1alpha1.Webhook) { w.Spec.ForProvider.Events = e }
+}
+
+func withSecretRef(ref *xpv1.SecretKeySelector) webhookModifier {
+	return func(w *v1alpha1.Webhook) { w.Spec.ForProvider.SecretRef = ref }
+}
+
+func withConditions(c ...xpv1.Condition) webhookModifier {
+	return func(w *v1alpha1.Webhook) { w.Status.SetConditions(c...) }
+}
+
+func withID(id int) webhookModifier {
+	return func(w *v1alpha1.Webhook) { w.Status.AtProvider.ID = id }
+}
+
+func webhook(m ...webhookModifier) *v1alpha1.Webhook {
+	cr := &v1alpha1.Webhook{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.WebhookSpec{
+			ForProvider: v1alpha1.WebhookParameters{
+				ProjectKey:     projectKey,
+				RepositorySlug: slug,
+				URL:            hookURL,
+				Events:         []string{"repo:refs_changed", "pr:opened"},
+			},
+		},
+	}
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		cr  *v1alpha1.Webhook
+		err error
+	}
+
+	observed := &bitbucket.Webhook{
+		ID:     3,
+		Name:   name,
+		URL:    hookURL,
+		Events: []string{"pr:opened", "repo:refs_changed"},
+		Active: true,
+	}
+
+	cases := map[string]struct {
+		reason   string
+		webhooks bitbucket.WebhookService
+		args     args
+		want     want
+	}{
+		"NotCreated": {
+			reason: "We should report a webhook without an ID as not existing.",
+			args:   args{ctx: context.Background(), mg: webhook()},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: webhook(),
+			},
+		},
+		"NotFound": {
+			reason: "We should report a deleted webhook as not existing.",
+			webhooks: &fake.MockWebhookService{
+				MockGetWebhook: func(context.Context, *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+					return nil, bitbucket.ErrNotFound
+				},
+			},
+			args: args{ctx: context.Background(), mg: webhook(withExternalName("3"))},
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: false},
+				cr: webhook(withExternalName("3")),
+			},
+		},
+		"GetFailed": {
+			reason: "We should return errors fetching the webhook.",
+			webhooks: &fake.MockWebhookService{
+				MockGetWebhook: func(context.Context, *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+					return nil, errBoom
+				},
+			},
+			args: args{ctx: context.Background(), mg: webhook(withExternalName("3"))},
+			want: want{
+				cr:  webhook(withExternalName("3")),
+				err: errors.Wrap(errBoom, errGetWebhook),
+			},
+		},
+		"UpToDate": {
+			reason: "We should ignore the order of the events.",
+			webhooks: &fake.MockWebhookService{
+				MockGetWebhook: func(_ context.Context, req *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+					if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 3 {
+						return nil, bitbucket.ErrNotFound
+					}
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: webhook(withExternalName("3"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: webhook(withExternalName("3"), withConditions(xpv1.Available()), withID(3)),
+			},
+		},
+		"EventsDrift": {
+			reason: "We should report a webhook triggered by other events as not up to date.",
+			webhooks: &fake.MockWebhookService{
+				MockGetWebhook: func(context.Context, *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: webhook(withExternalName("3"), withEvents("repo:refs_changed"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: webhook(withExternalName("3"), withEvents("repo:refs_changed"), withConditions(xpv1.Available()), withID(3)),
+			},
+		},
+		"URLDrift": {
+			reason: "We should report a webhook posting to another URL as not up to date.",
+			webhooks: &fake.MockWebhookService{
+				MockGetWebhook: func(context.Context, *bitbucket.GetWebhookRequest) (*bitbucket.Webhook, error) {
+					return observed, nil
+				},
+			},
+			args: args{ctx: context.Background(), mg: webhook(withExternalName("3"), withURL("https://other.example.com"))},
+			want: want{
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				cr: webhook(withExternalName("3"), withURL("https://other.example.com"), withConditions(xpv1.Available()), withID(3)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{webhooks: tc.webhooks}
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ref := &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Name: "ci-webhook", Namespace: "crossplane-system"},
+		Key:             "secret",
+	}
+	kube := kubefake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-webhook", Namespace: "crossplane-system"},
+		Data:       map[string][]byte{"secret": []byte("s3cr3t")},
+	}).Build()
+
+	type want struct {
+		cr  *v1alpha1.Webhook
+		err error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		kube     client.Client
+		webhooks bitbucket.WebhookService
+		mg       resource.Managed
+		want     want
+	}{
+		"CreatedWithSecret": {
+			reason: "We should sign the payloads with the referenced secret and use the ID of the webhook as external name.",
+			kube:   kube,
+			webhooks: &fake.MockWebhookService{
+				MockCreateWebhook: func(_ context.Context, req *bitbucket.CreateWebhookRequest) (*bitbucket.Webhook, error) {
+					if req.Configuration.Secret != "s3cr3t" || req.Name != name || !req.Active {
+						return nil, errBoom
+					}
+					return &bitbucket.Webhook{ID: 3}, nil
+				},
+			},
+			mg:   webhook(withSecretRef(ref)),
+			want: want{cr: webhook(withSecretRef(ref), withExternalName("3"), withConditions(xpv1.Creating()))},
+		},
+		"SecretMissing": {
+			reason: "We should return an error if the referenced secret can not be read.",
+			kube:   kubefake.NewClientBuilder().Build(),
+			mg:     webhook(withSecretRef(ref)),
+			want: want{
+				cr:  webhook(withSecretRef(ref), withConditions(xpv1.Creating())),
+				err: errors.Wrap(errors.New(`secrets "ci-webhook" not found`), errGetSecret),
+			},
+		},
+		"CreateFailed": {
+			reason: "We should return errors creating the webhook.",
+			webhooks: &fake.MockWebhookService{
+				MockCreateWebhook: func(context.Context, *bitbucket.CreateWebhookRequest) (*bitbucket.Webhook, error) {
+					return nil, errBoom
+				},
+			},
+			mg: webhook(),
+			want: want{
+				cr:  webhook(withConditions(xpv1.Creating())),
+				err: errors.Wrap(errBoom, errCreateWebhook),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{kube: tc.kube, webhooks: tc.webhooks}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ref := &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Name: "ci-webhook", Namespace: "crossplane-system"},
+		Key:             "secret",
+	}
+	kube := kubefake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-webhook", Namespace: "crossplane-system"},
+		Data:       map[string][]byte{"secret": []byte("s3cr3t")},
+	}).Build()
+
+	cases := map[string]struct {
+		reason   string
+		kube     client.Client
+		webhooks bitbucket.WebhookService
+		mg       resource.Managed
+		want     error
+	}{
+		"Updated": {
+			reason: "We should update the webhook of the external name with the desired fields and secret.",
+			kube:   kube,
+			webhooks: &fake.MockWebhookService{
+				MockUpdateWebhook: func(_ context.Context, req *bitbucket.UpdateWebhookRequest) (*bitbucket.Webhook, error) {
+					want := &bitbucket.UpdateWebhookRequest{
+						ProjectKey:     projectKey,
+						RepositorySlug: slug,
+						ID:             3,
+						Name:           name,
+						URL:            "https://ci.example.com/other",
+						Events:         []string{"pr:merged"},
+						Active:         true,
+						Configuration:  bitbucket.WebhookConfiguration{Secret: "s3cr3t"},
+					}
+					if diff := cmp.Diff(want, req); diff != "" {
+						return nil, errors.New(diff)
+					}
+					return &bitbucket.Webhook{ID: 3}, nil
+				},
+			},
+			mg: webhook(withExternalName("3"), withSecretRef(ref), withURL("https://ci.example.com/other"), withEvents("pr:merged")),
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			mg:     webhook(withExternalName("ci")),
+			want:   errors.Wrap(errors.New(`strconv.Atoi: parsing "ci": invalid syntax`), errInvalidID),
+		},
+		"SecretMissing": {
+			reason: "We should return an error if the referenced secret can not be read.",
+			kube:   kubefake.NewClientBuilder().Build(),
+			mg:     webhook(withExternalName("3"), withSecretRef(ref)),
+			want:   errors.Wrap(errors.New(`secrets "ci-webhook" not found`), errGetSecret),
+		},
+		"UpdateFailed": {
+			reason: "We should return errors updating the webhook.",
+			webhooks: &fake.MockWebhookService{
+				MockUpdateWebhook: func(context.Context, *bitbucket.UpdateWebhookRequest) (*bitbucket.Webhook, error) {
+					return nil, errBoom
+				},
+			},
+			mg:   webhook(withExternalName("3")),
+			want: errors.Wrap(errBoom, errUpdateWebhook),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{kube: tc.kube, webhooks: tc.webhooks}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		webhooks bitbucket.WebhookService
+		mg       resource.Managed
+		want     error
+	}{
+		"Deleted": {
+			reason: "We should delete the webhook of the external name.",
+			webhooks: &fake.MockWebhookService{
+				MockDeleteWebhook: func(_ context.Context, req *bitbucket.DeleteWebhookRequest) error {
+					if req.ProjectKey != projectKey || req.RepositorySlug != slug || req.ID != 3 {
+						return errBoom
+					}
+					return nil
+				},
+			},
+			mg: webhook(withExternalName("3")),
+		},
+		"AlreadyDeleted": {
+			reason: "We should not return an error when the webhook is already gone.",
+			webhooks: &fake.MockWebhookService{
+				MockDeleteWebhook: func(context.Context, *bitbucket.DeleteWebhookRequest) error {
+					return bitbucket.ErrNotFound
+				},
+			},
+			mg: webhook(withExternalName("3")),
+		},
+		"InvalidID": {
+			reason: "We should return an error if the external name is not an ID.",
+			mg:     webhook(withExternalName("ci")),
+			want:   errors.Wrap(errors.New(`strconv.Atoi: parsing "ci": invalid syntax`), errInvalidID),
+		},
+		"DeleteFailed": {
+			reason: "We should return errors deleting the webhook.",
+			webhooks: &fake.MockWebhookService{
+				MockDeleteWebhook: func(context.Context, *bitbucket.DeleteWebhookRequest) error {
+					return errBoom
+				},
+			},
+			mg:   webhook(withExternalName("3")),
+			want: errors.Wrap(errBoom, errDeleteWebhook),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{webhooks: tc.webhooks}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	properties := func(version string) *fake.MockApplicationService {
+		return &fake.MockApplicationService{
+			MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+				return &bitbucket.ApplicationProperties{Version: version}, nil
+			},
+		}
+	}
+	projectWebhook := func(w *v1alpha1.Webhook) { w.Spec.ForProvider.RepositorySlug = "" }
+
+	type want struct {
+		supported bool
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Webhook
+		app    *fake.MockApplicationService
+		want   want
+	}{
+		"RepositoryWebhook": {
+			reason: "We should manage repository webhooks without checking the version of the server.",
+			cr:     webhook(),
+			want:   want{supported: true},
+		},
+		"ProjectWebhook": {
+			reason: "We should manage project webhooks of a server that supports them.",
+			cr:     webhook(projectWebhook),
+			app:    properties("7.13.0"),
+			want:   want{supported: true},
+		},
+		"ProjectWebhookUnsupported": {
+			reason: "We should not manage project webhooks of a server that does not support them.",
+			cr:     webhook(projectWebhook),
+			app:    properties("7.12.0"),
+		},
+		"CapabilitiesFailed": {
+			reason: "We should return errors reading the version of the server.",
+			cr:     webhook(projectWebhook),
+			app: &fake.MockApplicationService{
+				MockGetApplicationProperties: func(context.Context) (*bitbucket.ApplicationProperties, error) {
+					return nil, errBoom
+				},
+			},
+			want: want{err: errors.Wrap(fmt.Errorf("error detecting server version: %w", errBoom), errGetCapabilities)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &connector{
+				usage: resource.TrackerFn(func(context.Context, resource.Managed) error { return nil }),
+				newClientFn: func(context.Context, client.Client, resource.Managed) (*bitbucket.Client, error) {
+					// a nil Application fails the test of webhooks that
+					// must not check the version of the server
+					bc := &bitbucket.Client{Webhooks: &fake.MockWebhookService{}}
+					if tc.app != nil {
+						bc.Application = tc.app
+					}
+					return bc, nil
+				},
+			}
+			got, err := c.Connect(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if _, supported := got.(*external); supported != tc.want.supported {
+				t.Errorf("\n%s\nc.Connect(...): want a client managing webhooks %t, got %T", tc.reason, tc.want.supported, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package refmatcher builds the ref matchers branch restrictions and default
+// reviewer conditions select refs with.
+package refmatcher
+
+import (
+	"strings"
+
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+// BranchRefPrefix is the prefix of the fully qualified ref of a branch.
+const BranchRefPrefix = "refs/heads/"
+
+// Generate returns the matcher of type typ matching id.
+func Generate(typ, id string) bitbucket.RefMatcher {
+	id = ID(typ, id)
+	return bitbucket.RefMatcher{
+		ID:        id,
+		DisplayID: strings.TrimPrefix(id, BranchRefPrefix),
+		Type:      bitbucket.RefMatcherType{ID: typ},
+		Active:    true,
+	}
+}
+
+// ID returns the ID bitbucket uses for the matcher, which for BRANCH matchers
+// is the fully qualified ref of the branch.
+func ID(typ, id string) string {
+	switch {
+	case typ == bitbucket.MatcherAnyRef:
+		return bitbucket.AnyRefMatcherID
+	case typ == bitbucket.MatcherBranch && !strings.HasPrefix(id, "refs/"):
+		return BranchRefPrefix + id
+	}
+	return id
+}
+
+// Matches reports whether the observed matcher is the matcher of type typ
+// matching id.
+func Matches(typ, id string, observed bitbucket.RefMatcher) bool {
+	return typ == observed.Type.ID && ID(typ, id) == observed.ID
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package refmatcher
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/tomas-mota/provider-bitbucketserver/internal/bitbucket"
+)
+
+func TestGenerate(t *testing.T) {
+	type args struct {
+		typ string
+		id  string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bitbucket.RefMatcher
+	}{
+		"BranchName": {
+			reason: "We should qualify the name of a branch with refs/heads/.",
+			args:   args{typ: bitbucket.MatcherBranch, id: "main"},
+			want: bitbucket.RefMatcher{
+				ID:        "refs/heads/main",
+				DisplayID: "main",
+				Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch},
+				Active:    true,
+			},
+		},
+		"BranchRef": {
+			reason: "We should keep the fully qualified ref of a branch.",
+			args:   args{typ: bitbucket.MatcherBranch, id: "refs/heads/main"},
+			want: bitbucket.RefMatcher{
+				ID:        "refs/heads/main",
+				DisplayID: "main",
+				Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherBranch},
+				Active:    true,
+			},
+		},
+		"Pattern": {
+			reason: "We should pass patterns through unchanged.",
+			args:   args{typ: "PATTERN", id: "release/*"},
+			want: bitbucket.RefMatcher{
+				ID:        "release/*",
+				DisplayID: "release/*",
+				Type:      bitbucket.RefMatcherType{ID: "PATTERN"},
+				Active:    true,
+			},
+		},
+		"AnyRef": {
+			reason: "We should use the fixed id of ANY_REF matchers.",
+			args:   args{typ: bitbucket.MatcherAnyRef},
+			want: bitbucket.RefMatcher{
+				ID:        bitbucket.AnyRefMatcherID,
+				DisplayID: bitbucket.AnyRefMatcherID,
+				Type:      bitbucket.RefMatcherType{ID: bitbucket.MatcherAnyRef},
+				Active:    true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Generate(tc.args.typ, tc.args.id)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGenerate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if !Matches(tc.args.typ, tc.args.id, got) {
+				t.Errorf("\n%s\nMatches(...): the generated matcher should match\n", tc.reason)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	observed := Generate(bitbucket.MatcherBranch, "main")
+
+	cases := map[string]struct {
+		reason string
+		typ    string
+		id     string
+		want   bool
+	}{
+		"SameBranch": {
+			reason: "A branch name should match the observed ref of the branch.",
+			typ:    bitbucket.MatcherBranch,
+			id:     "main",
+			want:   true,
+		},
+		"OtherBranch": {
+			reason: "Another branch should not match.",
+			typ:    bitbucket.MatcherBranch,
+			id:     "develop",
+			want:   false,
+		},
+		"OtherType": {
+			reason: "A matcher of another type should not match.",
+			typ:    "PATTERN",
+			id:     "refs/heads/main",
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, Matches(tc.typ, tc.id, observed)); diff != "" {
+				t.Errorf("\n%s\nMatches(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sshkey generates the SSH keypairs of access keys.
+package sshkey
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// bits of the generated RSA keys, RSA being the key type every bitbucket
+	// version accepts
+	bits = 4096
+
+	errGenerateKey = "cannot generate private key"
+	errPublicKey   = "cannot derive public key"
+)
+
+// Connection secret keys the keypair of an access key is published under.
+const (
+	PublicKey  = "publicKey"
+	PrivateKey = "privateKey"
+)
+
+// GenerateKeyPair generates an RSA keypair, returning the public key in the
+// authorized_keys format and the PEM encoded private key.
+func GenerateKeyPair() (publicKey []byte, privateKey []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerateKey)
+	}
+
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errPublicKey)
+	}
+
+	privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return ssh.MarshalAuthorizedKey(pub), privateKey, nil
+}